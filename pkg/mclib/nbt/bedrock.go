@@ -0,0 +1,59 @@
+package nbt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+const bedrockHeaderSize = 8
+
+// ReadBedrockLevelDat reads the level.dat of a Bedrock world, which holds the
+// storage version and payload length followed by uncompressed little-endian
+// NBT.
+func ReadBedrockLevelDat(r io.Reader) (int32, *File, error) {
+	header := make([]byte, bedrockHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, nil, fmt.Errorf("read bedrock header: %w", err)
+	}
+	version := int32(binary.LittleEndian.Uint32(header))
+	length := int64(binary.LittleEndian.Uint32(header[4:]))
+
+	lr := &io.LimitedReader{R: r, N: length}
+	f, err := ReadRawFromStreamWithOptions(lr, ReadOptions{Endianness: LittleEndian})
+	if err != nil {
+		return 0, nil, err
+	}
+	if lr.N != 0 {
+		return 0, nil, fmt.Errorf("read nbt data: %d bytes of declared length left", lr.N)
+	}
+	return version, f, nil
+}
+
+// ReadBedrockFromStream reads the level.dat of a Bedrock world like
+// ReadBedrockLevelDat, discarding the storage version.
+func ReadBedrockFromStream(r io.Reader) (*File, error) {
+	_, f, err := ReadBedrockLevelDat(r)
+	return f, err
+}
+
+// WriteBedrockLevelDat writes f as Bedrock level.dat including the header
+// read by ReadBedrockLevelDat.
+func WriteBedrockLevelDat(w io.Writer, version int32, f *File) error {
+	var body bytes.Buffer
+	if err := WriteToStreamWithOptions(&body, f, WriteOptions{Endianness: LittleEndian}); err != nil {
+		return err
+	}
+
+	header := make([]byte, bedrockHeaderSize)
+	binary.LittleEndian.PutUint32(header, uint32(version))
+	binary.LittleEndian.PutUint32(header[4:], uint32(body.Len()))
+	if _, err := w.Write(header); err != nil {
+		return fmt.Errorf("write bedrock header: %w", err)
+	}
+	if _, err := body.WriteTo(w); err != nil {
+		return fmt.Errorf("write nbt data: %w", err)
+	}
+	return nil
+}
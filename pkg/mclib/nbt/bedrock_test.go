@@ -0,0 +1,94 @@
+package nbt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestBedrockLevelDatRoundTrip(t *testing.T) {
+	f := testFile(t)
+	f.RootName = "bedrock"
+
+	var buf bytes.Buffer
+	if err := WriteBedrockLevelDat(&buf, 10, f); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	if version := binary.LittleEndian.Uint32(data); version != 10 {
+		t.Errorf("header version = %d, want 10", version)
+	}
+	if length := binary.LittleEndian.Uint32(data[4:]); int(length) != len(data)-bedrockHeaderSize {
+		t.Errorf("header length = %d, want %d", length, len(data)-bedrockHeaderSize)
+	}
+
+	// the reader must not consume data beyond the declared length
+	r := bytes.NewReader(append(bytes.Clone(data), 0xff))
+	version, readFile, err := ReadBedrockLevelDat(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != 10 || readFile.RootName != "bedrock" || !Equal(readFile.Root, f.Root) {
+		t.Errorf("read version %d, root %q: %v", version, readFile.RootName, ToSNBT(readFile.Root))
+	}
+	if r.Len() != 1 {
+		t.Errorf("%d bytes left after the level.dat, want 1", r.Len())
+	}
+}
+
+func TestReadBedrockLevelDatLengthMismatch(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteBedrockLevelDat(&buf, 10, testFile(t)); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	longer := bytes.Clone(data)
+	binary.LittleEndian.PutUint32(longer[4:], uint32(len(data)-bedrockHeaderSize+4))
+	if _, _, err := ReadBedrockLevelDat(bytes.NewReader(append(longer, 0, 0, 0, 0))); err == nil {
+		t.Errorf("expected error for declared length beyond the data")
+	}
+
+	shorter := bytes.Clone(data)
+	binary.LittleEndian.PutUint32(shorter[4:], uint32(len(data)-bedrockHeaderSize-1))
+	if _, _, err := ReadBedrockLevelDat(bytes.NewReader(shorter)); err == nil {
+		t.Errorf("expected error for declared length within the data")
+	}
+}
+
+func TestReadBedrockMatchesJava(t *testing.T) {
+	// {LevelName:"w",SpawnY:64,Time:100L,rainLevel:0.5f} in little-endian
+	body := []byte{0x0a, 0x00, 0x00}
+	body = append(body, 0x08, 0x09, 0x00)
+	body = append(body, "LevelName"...)
+	body = append(body, 0x01, 0x00, 'w')
+	body = append(body, 0x03, 0x06, 0x00)
+	body = append(body, "SpawnY"...)
+	body = append(body, 0x40, 0x00, 0x00, 0x00)
+	body = append(body, 0x04, 0x04, 0x00)
+	body = append(body, "Time"...)
+	body = append(body, 0x64, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00)
+	body = append(body, 0x05, 0x09, 0x00)
+	body = append(body, "rainLevel"...)
+	body = append(body, 0x00, 0x00, 0x00, 0x3f)
+	body = append(body, 0x00)
+	data := binary.LittleEndian.AppendUint32(binary.LittleEndian.AppendUint32(nil, 10), uint32(len(body)))
+	data = append(data, body...)
+
+	bedrock, err := ReadBedrockFromStream(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	java, err := ParseSNBT(`{LevelName:"w",SpawnY:64,Time:100L,rainLevel:0.5f}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Equal(bedrock.Root, java.Root) {
+		t.Errorf("bedrock tree %s differs from java tree %s", ToSNBT(bedrock.Root), ToSNBT(java.Root))
+	}
+
+	// the same tree in big-endian is not understood as little-endian
+	if _, err := ReadRawFromStreamWithOptions(bytes.NewReader(encodeFile(t, java)), ReadOptions{Endianness: LittleEndian}); err == nil {
+		t.Errorf("expected error reading big-endian data as little-endian")
+	}
+}
@@ -0,0 +1,149 @@
+package nbt
+
+import (
+	"cmp"
+	"fmt"
+	"math/bits"
+	"slices"
+)
+
+const biomeCellsPerSection = 4 * 4 * 4
+
+// legacyBiomeIDs maps the numeric biome ids used before 1.18.
+var legacyBiomeIDs = map[int32]string{
+	0: "ocean", 1: "plains", 2: "desert", 3: "mountains", 4: "forest", 5: "taiga",
+	6: "swamp", 7: "river", 8: "nether_wastes", 9: "the_end", 10: "frozen_ocean",
+	11: "frozen_river", 12: "snowy_tundra", 13: "snowy_mountains", 14: "mushroom_fields",
+	15: "mushroom_field_shore", 16: "beach", 17: "desert_hills", 18: "wooded_hills",
+	19: "taiga_hills", 20: "mountain_edge", 21: "jungle", 22: "jungle_hills",
+	23: "jungle_edge", 24: "deep_ocean", 25: "stone_shore", 26: "snowy_beach",
+	27: "birch_forest", 28: "birch_forest_hills", 29: "dark_forest", 30: "snowy_taiga",
+	31: "snowy_taiga_hills", 32: "giant_tree_taiga", 33: "giant_tree_taiga_hills",
+	34: "wooded_mountains", 35: "savanna", 36: "savanna_plateau", 37: "badlands",
+	38: "wooded_badlands_plateau", 39: "badlands_plateau", 40: "small_end_islands",
+	41: "end_midlands", 42: "end_highlands", 43: "end_barrens", 44: "warm_ocean",
+	45: "lukewarm_ocean", 46: "cold_ocean", 47: "deep_warm_ocean", 48: "deep_lukewarm_ocean",
+	49: "deep_cold_ocean", 50: "deep_frozen_ocean", 127: "the_void",
+	129: "sunflower_plains", 130: "desert_lakes", 131: "gravelly_mountains",
+	132: "flower_forest", 133: "taiga_mountains", 134: "swamp_hills", 140: "ice_spikes",
+	149: "modified_jungle", 151: "modified_jungle_edge", 155: "tall_birch_forest",
+	156: "tall_birch_hills", 157: "dark_forest_hills", 158: "snowy_taiga_mountains",
+	160: "giant_spruce_taiga", 161: "giant_spruce_taiga_hills",
+	162: "modified_gravelly_mountains", 163: "shattered_savanna",
+	164: "shattered_savanna_plateau", 165: "eroded_badlands",
+	166: "modified_wooded_badlands_plateau", 167: "modified_badlands_plateau",
+	168: "bamboo_jungle", 169: "bamboo_jungle_hills", 170: "soul_sand_valley",
+	171: "crimson_forest", 172: "warped_forest", 173: "basalt_deltas",
+	174: "dripstone_caves", 175: "lush_caves",
+}
+
+// ChunkBiomes returns the namespaced biome ids of a chunk in storage order.
+// Chunks before 1.15 store one biome per column, indexed by x + 16*z. Later
+// chunks store 4x4x4 block cells from the bottom of the world upwards, indexed
+// by x + 4*z + 16*y in cell coordinates.
+func ChunkBiomes(chunk *CompoundNode) ([]string, error) {
+	data, legacy := chunkData(chunk)
+	if legacy {
+		return legacyChunkBiomes(data)
+	}
+
+	sections, ok := data.Values["sections"].(*ListNode)
+	if !ok {
+		return nil, fmt.Errorf("missing sections")
+	}
+	sorted := make([]*CompoundNode, 0, len(sections.Values))
+	for i, node := range sections.Values {
+		section, ok := node.(*CompoundNode)
+		if !ok {
+			return nil, fmt.Errorf("section %d is not a compound", i)
+		}
+		sorted = append(sorted, section)
+	}
+	slices.SortStableFunc(sorted, func(a, b *CompoundNode) int {
+		y1, _ := Byte(a.Values["Y"])
+		y2, _ := Byte(b.Values["Y"])
+		return cmp.Compare(int8(y1), int8(y2))
+	})
+
+	biomes := make([]string, 0, len(sorted)*biomeCellsPerSection)
+	for _, section := range sorted {
+		y, _ := Byte(section.Values["Y"])
+		sectionBiomes, err := sectionBiomes(section)
+		if err != nil {
+			return nil, fmt.Errorf("section %d: %w", int8(y), err)
+		}
+		biomes = append(biomes, sectionBiomes...)
+	}
+	return biomes, nil
+}
+
+func sectionBiomes(section *CompoundNode) ([]string, error) {
+	biomesNode, ok := section.Values["biomes"].(*CompoundNode)
+	if !ok {
+		return nil, fmt.Errorf("missing biomes")
+	}
+	palette, ok := biomesNode.Values["palette"].(*ListNode)
+	if !ok || len(palette.Values) == 0 {
+		return nil, fmt.Errorf("missing biome palette")
+	}
+	ids := make([]string, len(palette.Values))
+	for i, node := range palette.Values {
+		if ids[i], ok = Str(node); !ok {
+			return nil, fmt.Errorf("biome palette entry %d is not a string", i)
+		}
+	}
+
+	biomes := make([]string, biomeCellsPerSection)
+	bitsPerCell := bits.Len(uint(len(ids) - 1))
+	if bitsPerCell == 0 {
+		// a single biome does not need any data
+		for i := range biomes {
+			biomes[i] = ids[0]
+		}
+		return biomes, nil
+	}
+
+	packed, ok := biomesNode.Values["data"].(*LongArrayNode)
+	valuesPerLong := 64 / bitsPerCell
+	if !ok || len(packed.Values) != (biomeCellsPerSection+valuesPerLong-1)/valuesPerLong {
+		return nil, fmt.Errorf("missing or malformed biome data for %d palette entries", len(ids))
+	}
+	mask := uint64(1)<<bitsPerCell - 1
+	for i := range biomes {
+		index := int(uint64(packed.Values[i/valuesPerLong]) >> (bitsPerCell * (i % valuesPerLong)) & mask)
+		if index >= len(ids) {
+			return nil, fmt.Errorf("biome palette index %d out of range", index)
+		}
+		biomes[i] = ids[index]
+	}
+	return biomes, nil
+}
+
+func legacyChunkBiomes(data *CompoundNode) ([]string, error) {
+	numericIDs := make([]int32, 0)
+	switch node := data.Values["Biomes"].(type) {
+	case *ByteArrayNode:
+		// before 1.13
+		for _, val := range node.Values {
+			numericIDs = append(numericIDs, int32(val))
+		}
+	case *IntArrayNode:
+		for _, childNode := range node.Values {
+			if val, ok := Int(childNode); ok {
+				numericIDs = append(numericIDs, val)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("missing biomes")
+	}
+
+	biomes := make([]string, len(numericIDs))
+	for i, numericID := range numericIDs {
+		id, ok := legacyBiomeIDs[numericID]
+		if !ok {
+			return nil, fmt.Errorf("unknown biome id %d at index %d", numericID, i)
+		}
+		biomes[i] = "minecraft:" + id
+	}
+	return biomes, nil
+}
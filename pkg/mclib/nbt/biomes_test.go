@@ -0,0 +1,75 @@
+package nbt
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestChunkBiomesLegacy(t *testing.T) {
+	byteBiomes := make([]byte, 256)
+	intBiomes := make([]Node, 1024)
+	want := make([]string, 1024)
+	for i := range intBiomes {
+		id := int32(i % 3)
+		if i < len(byteBiomes) {
+			byteBiomes[i] = byte(id)
+		}
+		intBiomes[i] = &IntNode{Value: id}
+		want[i] = []string{"minecraft:ocean", "minecraft:plains", "minecraft:desert"}[id]
+	}
+
+	for name, biomesNode := range map[string]Node{
+		// before 1.13, one biome per column
+		"byte array": &ByteArrayNode{Values: byteBiomes},
+		// 1.15 to 1.17, one biome per cell
+		"int array": &IntArrayNode{Values: intBiomes},
+	} {
+		chunk := &CompoundNode{}
+		chunk.Set("Level", &CompoundNode{Values: map[string]Node{"Biomes": biomesNode}})
+		biomes, err := ChunkBiomes(chunk)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if length := len(biomes); !slices.Equal(biomes, want[:length]) || (length != 256 && length != 1024) {
+			t.Errorf("%s: ChunkBiomes() = %v", name, biomes)
+		}
+	}
+
+	chunk := &CompoundNode{}
+	chunk.Set("Level", &CompoundNode{Values: map[string]Node{"Biomes": &IntArrayNode{Values: []Node{&IntNode{Value: 99}}}}})
+	if _, err := ChunkBiomes(chunk); err == nil {
+		t.Errorf("expected error for unknown numeric biome")
+	}
+}
+
+func TestChunkBiomesPalettized(t *testing.T) {
+	// sections are given out of order and sorted by Y
+	f, err := ParseSNBT(`{DataVersion:3465,sections:[` +
+		`{Y:-3b,biomes:{palette:["minecraft:plains","minecraft:river"],data:[L;4294967295L]}},` +
+		`{Y:-4b,biomes:{palette:["minecraft:deep_dark"]}}]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	biomes, err := ChunkBiomes(f.Root.(*CompoundNode))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := make([]string, 0, 128)
+	for range 64 {
+		want = append(want, "minecraft:deep_dark")
+	}
+	for i := range 64 {
+		if i < 32 {
+			want = append(want, "minecraft:river")
+		} else {
+			want = append(want, "minecraft:plains")
+		}
+	}
+	if !slices.Equal(biomes, want) {
+		t.Errorf("ChunkBiomes() = %v\nwant %v", biomes, want)
+	}
+
+	if _, err := ChunkBiomes(testChunk(t)); err == nil {
+		t.Errorf("expected error for sections without biomes")
+	}
+}
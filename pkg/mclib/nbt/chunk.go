@@ -0,0 +1,233 @@
+package nbt
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+const blocksPerSection = 16 * 16 * 16
+
+type ChunkIntegrityReport struct {
+	// NeedsLighting is set if the game has not computed the light of the chunk.
+	NeedsLighting bool
+	// Problems describes all signs of corruption found in the chunk.
+	Problems []string
+}
+
+func (r ChunkIntegrityReport) Corrupt() bool { return len(r.Problems) > 0 }
+
+func (r *ChunkIntegrityReport) addProblem(format string, args ...interface{}) {
+	r.Problems = append(r.Problems, fmt.Sprintf(format, args...))
+}
+
+// chunkData returns the compound holding the chunk data, which is nested in
+// "Level" for chunks before 1.18.
+func chunkData(chunk *CompoundNode) (*CompoundNode, bool) {
+	if level, ok := chunk.Values["Level"].(*CompoundNode); ok {
+		return level, true
+	}
+	return chunk, false
+}
+
+// ChunkIntegrity checks the top-level compound of a chunk for missing keys
+// and inconsistent block data.
+func ChunkIntegrity(chunk *CompoundNode) ChunkIntegrityReport {
+	report := ChunkIntegrityReport{}
+	data, legacy := chunkData(chunk)
+
+	if _, ok := chunk.Values["DataVersion"].(*IntNode); !ok && !legacy {
+		report.addProblem("missing DataVersion")
+	}
+	for _, key := range []string{"xPos", "zPos"} {
+		if _, ok := data.Values[key].(*IntNode); !ok {
+			report.addProblem("missing %s", key)
+		}
+	}
+
+	sectionsKey := "sections"
+	if legacy {
+		sectionsKey = "Sections"
+	}
+	if sections, ok := data.Values[sectionsKey].(*ListNode); ok {
+		for i, node := range sections.Values {
+			section, ok := node.(*CompoundNode)
+			if !ok {
+				report.addProblem("section %d is not a compound", i)
+				continue
+			}
+			checkSection(&report, i, section)
+		}
+	} else {
+		report.addProblem("missing sections")
+	}
+
+	checkHeightmaps(&report, data)
+
+	if lightOn, ok := data.Values["isLightOn"].(*ByteNode); ok {
+		report.NeedsLighting = lightOn.Value == 0
+	} else if lightPopulated, ok := data.Values["LightPopulated"].(*ByteNode); ok {
+		report.NeedsLighting = lightPopulated.Value == 0
+	} else {
+		report.NeedsLighting = true
+	}
+	return report
+}
+
+func checkSection(report *ChunkIntegrityReport, index int, section *CompoundNode) {
+	// since 1.18 the palette is nested in block_states
+	palette, _ := section.Values["Palette"].(*ListNode)
+	blockStates, _ := section.Values["BlockStates"].(*LongArrayNode)
+	if states, ok := section.Values["block_states"].(*CompoundNode); ok {
+		palette, _ = states.Values["palette"].(*ListNode)
+		blockStates, _ = states.Values["data"].(*LongArrayNode)
+	}
+
+	if palette == nil {
+		if blocks, ok := section.Values["Blocks"].(*ByteArrayNode); ok && len(blocks.Values) != blocksPerSection {
+			// numeric block ids before 1.13
+			report.addProblem("section %d: Blocks has length %d", index, len(blocks.Values))
+		}
+		return
+	}
+
+	if len(palette.Values) == 0 {
+		report.addProblem("section %d: empty palette", index)
+		return
+	}
+	if len(palette.Values) == 1 && blockStates == nil {
+		// a single block state does not need any data
+		return
+	}
+	if blockStates == nil {
+		report.addProblem("section %d: missing block state data for %d palette entries", index, len(palette.Values))
+		return
+	}
+
+	bitsPerBlock := max(4, bits.Len(uint(len(palette.Values)-1)))
+	// since 1.16 values do not span multiple longs
+	paddedLength := (blocksPerSection + 64/bitsPerBlock - 1) / (64 / bitsPerBlock)
+	spanningLength := blocksPerSection * bitsPerBlock / 64
+	if len(blockStates.Values) != paddedLength && len(blockStates.Values) != spanningLength {
+		report.addProblem("section %d: block state data has length %d, expected %d for %d palette entries",
+			index, len(blockStates.Values), paddedLength, len(palette.Values))
+	}
+}
+
+func checkHeightmaps(report *ChunkIntegrityReport, data *CompoundNode) {
+	// numeric height map before 1.13
+	if heightMap, ok := data.Values["HeightMap"].(*IntArrayNode); ok {
+		for i, node := range heightMap.Values {
+			if height, ok := node.(*IntNode); ok && height.Value < 0 {
+				report.addProblem("HeightMap: negative height %d at index %d", height.Value, i)
+				break
+			}
+		}
+	}
+
+	heightmaps, ok := data.Values["Heightmaps"].(*CompoundNode)
+	if !ok {
+		return
+	}
+	for _, name := range heightmaps.SortedKeys() {
+		heightmap, ok := heightmaps.Values[name].(*LongArrayNode)
+		if !ok {
+			report.addProblem("heightmap %s is not a long array", name)
+			continue
+		}
+		// 256 values of 9 bits, padded since 1.16
+		if len(heightmap.Values) != 37 && len(heightmap.Values) != 36 {
+			report.addProblem("heightmap %s has length %d", name, len(heightmap.Values))
+		}
+	}
+}
+
+const (
+	heightmapSize = 16 * 16
+	heightmapBits = 9
+)
+
+// ChunkHeightmap decodes the packed heightmap of the given name, e.g.
+// "WORLD_SURFACE" or "MOTION_BLOCKING", into one value per column indexed by
+// x + 16*z. Values are relative to the bottom of the world, which is y=-64
+// since 1.18.
+func ChunkHeightmap(chunk *CompoundNode, which string) ([]int, error) {
+	data, _ := chunkData(chunk)
+	heightmaps, ok := data.Values["Heightmaps"].(*CompoundNode)
+	if !ok {
+		return nil, fmt.Errorf("missing heightmaps")
+	}
+	heightmap, ok := heightmaps.Values[which].(*LongArrayNode)
+	if !ok {
+		return nil, fmt.Errorf("missing heightmap %s", which)
+	}
+
+	const valuesPerLong = 64 / heightmapBits
+	const mask = 1<<heightmapBits - 1
+	heights := make([]int, heightmapSize)
+	switch len(heightmap.Values) {
+	case (heightmapSize + valuesPerLong - 1) / valuesPerLong:
+		// since 1.16 values do not span multiple longs
+		for i := range heights {
+			val := uint64(heightmap.Values[i/valuesPerLong])
+			heights[i] = int(val >> (heightmapBits * (i % valuesPerLong)) & mask)
+		}
+	case heightmapSize * heightmapBits / 64:
+		for i := range heights {
+			bit := i * heightmapBits
+			val := uint64(heightmap.Values[bit/64]) >> (bit % 64)
+			if bit%64+heightmapBits > 64 {
+				val |= uint64(heightmap.Values[bit/64+1]) << (64 - bit%64)
+			}
+			heights[i] = int(val & mask)
+		}
+	default:
+		return nil, fmt.Errorf("heightmap %s has length %d", which, len(heightmap.Values))
+	}
+	return heights, nil
+}
+
+// LegacyBlockAt returns the numeric block id and metadata at the section-local
+// position of a section before 1.13. The optional Add array extends ids by four
+// more bits for mods.
+func LegacyBlockAt(section *CompoundNode, x, y, z int) (int, int, error) {
+	if x < 0 || x >= 16 || y < 0 || y >= 16 || z < 0 || z >= 16 {
+		return 0, 0, fmt.Errorf("position %d,%d,%d out of section bounds", x, y, z)
+	}
+	blocks, ok := section.Values["Blocks"].(*ByteArrayNode)
+	if !ok || len(blocks.Values) != blocksPerSection {
+		return 0, 0, fmt.Errorf("missing or malformed Blocks")
+	}
+
+	index := y*16*16 + z*16 + x
+	id := int(blocks.Values[index])
+	if add, ok := section.Values["Add"].(*ByteArrayNode); ok {
+		nibble, err := nibbleAt(add, index)
+		if err != nil {
+			return 0, 0, fmt.Errorf("read Add: %w", err)
+		}
+		id |= nibble << 8
+	}
+
+	data, ok := section.Values["Data"].(*ByteArrayNode)
+	if !ok {
+		return 0, 0, fmt.Errorf("missing Data")
+	}
+	meta, err := nibbleAt(data, index)
+	if err != nil {
+		return 0, 0, fmt.Errorf("read Data: %w", err)
+	}
+	return id, meta, nil
+}
+
+// nibbleAt returns the 4-bit value at index of an array holding two values per
+// byte, starting with the lower half.
+func nibbleAt(array *ByteArrayNode, index int) (int, error) {
+	if len(array.Values) != blocksPerSection/2 {
+		return 0, fmt.Errorf("nibble array has length %d", len(array.Values))
+	}
+	val := array.Values[index/2]
+	if index%2 == 1 {
+		val >>= 4
+	}
+	return int(val & 0x0f), nil
+}
@@ -0,0 +1,191 @@
+package nbt
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// longArraySNBT returns the SNBT of a long array of n zeros.
+func longArraySNBT(n int) string {
+	return "[L;" + strings.TrimSuffix(strings.Repeat("0L,", n), ",") + "]"
+}
+
+// testChunk returns a healthy chunk in the format since 1.18 with a
+// single-block section, a section of two block states and one heightmap.
+func testChunk(t *testing.T) *CompoundNode {
+	t.Helper()
+	f, err := ParseSNBT(fmt.Sprintf(`{DataVersion:3465,xPos:2,zPos:-1,Status:"minecraft:full",isLightOn:1b,`+
+		`sections:[{Y:-4b,block_states:{palette:[{Name:"minecraft:air"}]}},{Y:-3b,block_states:{palette:[{Name:"minecraft:air"},{Name:"minecraft:stone"}],data:%s}}],`+
+		`Heightmaps:{WORLD_SURFACE:%s}}`, longArraySNBT(256), longArraySNBT(37)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f.Root.(*CompoundNode)
+}
+
+func TestChunkIntegrityHealthy(t *testing.T) {
+	report := ChunkIntegrity(testChunk(t))
+	if report.Corrupt() || report.NeedsLighting {
+		t.Errorf("healthy chunk reported as %+v", report)
+	}
+}
+
+func TestChunkIntegrityCorrupt(t *testing.T) {
+	chunk := testChunk(t)
+	chunk.Delete("zPos")
+	chunk.Values["isLightOn"] = &ByteNode{Value: 0}
+	sections := chunk.Values["sections"].(*ListNode)
+	states := sections.Values[1].(*CompoundNode).Values["block_states"].(*CompoundNode)
+	states.Values["data"] = &LongArrayNode{Values: make([]int64, 100)}
+	sections.Values[0].(*CompoundNode).Values["block_states"].(*CompoundNode).Values["palette"] = &ListNode{}
+	chunk.Values["Heightmaps"].(*CompoundNode).Set("OCEAN_FLOOR", &LongArrayNode{Values: make([]int64, 10)})
+
+	report := ChunkIntegrity(chunk)
+	if !report.NeedsLighting {
+		t.Errorf("chunk without light is not reported")
+	}
+	want := []string{
+		"missing zPos",
+		"section 0: empty palette",
+		"section 1: block state data has length 100, expected 256 for 2 palette entries",
+		"heightmap OCEAN_FLOOR has length 10",
+	}
+	if strings.Join(report.Problems, "\n") != strings.Join(want, "\n") {
+		t.Errorf("problems:\n%s\nwant:\n%s", strings.Join(report.Problems, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestChunkIntegrityMissingKeys(t *testing.T) {
+	report := ChunkIntegrity(&CompoundNode{})
+	want := []string{"missing DataVersion", "missing xPos", "missing zPos", "missing sections"}
+	if strings.Join(report.Problems, "\n") != strings.Join(want, "\n") {
+		t.Errorf("problems = %q, want %q", report.Problems, want)
+	}
+	if !report.NeedsLighting {
+		t.Errorf("chunk without light data must need lighting")
+	}
+
+	// chunks before 1.18 nest their data in Level and have no DataVersion
+	f, err := ParseSNBT(`{Level:{xPos:0,zPos:0,Sections:[{Y:0b,Blocks:[B;1b,2b],Data:[B;]}],HeightMap:[I;5,-1],LightPopulated:1b}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report = ChunkIntegrity(f.Root.(*CompoundNode))
+	want = []string{"section 0: Blocks has length 2", "HeightMap: negative height -1 at index 1"}
+	if strings.Join(report.Problems, "\n") != strings.Join(want, "\n") || report.NeedsLighting {
+		t.Errorf("legacy chunk reported as %+v", report)
+	}
+}
+
+// packHeightmap packs 256 heights of 9 bits into longs, either padded to
+// whole values per long as since 1.16 or spanning longs as before.
+func packHeightmap(heights []int, padded bool) []int64 {
+	if padded {
+		packed := make([]int64, 37)
+		for i, height := range heights {
+			packed[i/7] |= int64(height) << (9 * (i % 7))
+		}
+		return packed
+	}
+	packed := make([]uint64, 36)
+	for i, height := range heights {
+		bit := 9 * i
+		packed[bit/64] |= uint64(height) << (bit % 64)
+		if bit%64+9 > 64 {
+			packed[bit/64+1] |= uint64(height) >> (64 - bit%64)
+		}
+	}
+	values := make([]int64, len(packed))
+	for i, val := range packed {
+		values[i] = int64(val)
+	}
+	return values
+}
+
+func TestChunkHeightmap(t *testing.T) {
+	heights := make([]int, 256)
+	for i := range heights {
+		heights[i] = (i * 37) % 384
+	}
+
+	for _, padded := range []bool{true, false} {
+		chunk := testChunk(t)
+		heightmaps := chunk.Values["Heightmaps"].(*CompoundNode)
+		heightmaps.Set("MOTION_BLOCKING", &LongArrayNode{Values: packHeightmap(heights, padded)})
+		decoded, err := ChunkHeightmap(chunk, "MOTION_BLOCKING")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(decoded, heights) {
+			t.Errorf("padded %v: decoded %v, want %v", padded, decoded, heights)
+		}
+	}
+
+	// the first long of heights 64 to 70 in the padded format
+	chunk := testChunk(t)
+	values := make([]int64, 37)
+	values[0] = 0x1188a44219088240
+	chunk.Values["Heightmaps"].(*CompoundNode).Set("WORLD_SURFACE", &LongArrayNode{Values: values})
+	decoded, err := ChunkHeightmap(chunk, "WORLD_SURFACE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{64, 65, 66, 67, 68, 69, 70, 0}; !slices.Equal(decoded[:8], want) {
+		t.Errorf("decoded %v, want %v", decoded[:8], want)
+	}
+
+	if _, err := ChunkHeightmap(chunk, "OCEAN_FLOOR"); err == nil {
+		t.Errorf("expected error for missing heightmap")
+	}
+	chunk.Values["Heightmaps"].(*CompoundNode).Set("OCEAN_FLOOR", &LongArrayNode{Values: make([]int64, 5)})
+	if _, err := ChunkHeightmap(chunk, "OCEAN_FLOOR"); err == nil {
+		t.Errorf("expected error for heightmap of wrong length")
+	}
+}
+
+func TestLegacyBlockAt(t *testing.T) {
+	blocks := make([]byte, 4096)
+	data := make([]byte, 2048)
+	add := make([]byte, 2048)
+	// x=2,y=2,z=1 at index 530 holds stone:3, x=3 at 531 a modded block 0x123:5
+	blocks[530], blocks[531] = 1, 0x23
+	data[265] = 0x53
+	add[265] = 0x10
+	section := &CompoundNode{}
+	section.Set("Y", &ByteNode{Value: 4})
+	section.Set("Blocks", &ByteArrayNode{Values: blocks})
+	section.Set("Data", &ByteArrayNode{Values: data})
+
+	tests := []struct {
+		x, y, z  int
+		id, meta int
+	}{
+		{2, 2, 1, 1, 3},
+		{3, 2, 1, 0x23, 5},
+		{0, 0, 0, 0, 0},
+	}
+	for _, test := range tests {
+		id, meta, err := LegacyBlockAt(section, test.x, test.y, test.z)
+		if err != nil || id != test.id || meta != test.meta {
+			t.Errorf("LegacyBlockAt(%d,%d,%d) = %d:%d, %v, want %d:%d", test.x, test.y, test.z, id, meta, err, test.id, test.meta)
+		}
+	}
+
+	section.Set("Add", &ByteArrayNode{Values: add})
+	if id, _, err := LegacyBlockAt(section, 3, 2, 1); err != nil || id != 0x123 {
+		t.Errorf("id with Add = %#x, %v, want 0x123", id, err)
+	}
+	if id, _, err := LegacyBlockAt(section, 2, 2, 1); err != nil || id != 1 {
+		t.Errorf("id with Add = %#x, %v, want 1", id, err)
+	}
+
+	if _, _, err := LegacyBlockAt(section, 16, 0, 0); err == nil {
+		t.Errorf("expected error for position out of bounds")
+	}
+	section.Set("Data", &ByteArrayNode{Values: make([]byte, 10)})
+	if _, _, err := LegacyBlockAt(section, 0, 0, 0); err == nil {
+		t.Errorf("expected error for malformed Data")
+	}
+}
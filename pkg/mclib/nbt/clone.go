@@ -0,0 +1,52 @@
+package nbt
+
+import "slices"
+
+// Clone returns a deep copy of n, so modifying the copy never affects n.
+// Nodes of types not defined by this package are returned as they are.
+func Clone(n Node) Node {
+	switch node := n.(type) {
+	case *ByteNode:
+		return &ByteNode{Value: node.Value}
+	case *ShortNode:
+		return &ShortNode{Value: node.Value}
+	case *IntNode:
+		return &IntNode{Value: node.Value}
+	case *LongNode:
+		return &LongNode{Value: node.Value}
+	case *FloatNode:
+		return &FloatNode{Value: node.Value}
+	case *DoubleNode:
+		return &DoubleNode{Value: node.Value}
+	case *ByteArrayNode:
+		return &ByteArrayNode{Values: slices.Clone(node.Values)}
+	case *StringNode:
+		return &StringNode{Value: node.Value}
+	case *ListNode:
+		return &ListNode{ElementType: node.ElementType, Values: cloneNodes(node.Values)}
+	case *CompoundNode:
+		values := make(map[string]Node, len(node.Values))
+		for key, childNode := range node.Values {
+			values[key] = Clone(childNode)
+		}
+		return &CompoundNode{Values: values, keyOrder: slices.Clone(node.keyOrder)}
+	case *IntArrayNode:
+		return &IntArrayNode{Values: cloneNodes(node.Values)}
+	case *LongArrayNode:
+		return &LongArrayNode{Values: slices.Clone(node.Values)}
+	case *UnknownNode:
+		return &UnknownNode{TagType: node.TagType, Raw: slices.Clone(node.Raw)}
+	}
+	return n
+}
+
+func cloneNodes(nodes []Node) []Node {
+	if nodes == nil {
+		return nil
+	}
+	clones := make([]Node, len(nodes))
+	for i, node := range nodes {
+		clones[i] = Clone(node)
+	}
+	return clones
+}
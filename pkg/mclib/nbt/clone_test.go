@@ -0,0 +1,36 @@
+package nbt
+
+import "testing"
+
+func TestClone(t *testing.T) {
+	const snbt = `{Data:{Player:{Pos:[1.0d,2.0d],Inventory:[{id:"minecraft:stone",Count:1b}]},Biomes:[B;1b,2b],Heights:[L;1L,2L],Ints:[I;1,2]}}`
+	f, err := ParseSNBT(snbt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	original := f.Root.(*CompoundNode)
+	want, err := ParseSNBT(snbt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clone := Clone(original).(*CompoundNode)
+	if !Equal(clone, original) {
+		t.Fatalf("clone %s differs from original", ToSNBT(clone))
+	}
+
+	data, _ := clone.Compound("Data")
+	player, _ := data.Compound("Player")
+	player.Set("Name", &StringNode{Value: "Steve"})
+	pos, _ := player.List("Pos")
+	pos.Values[0].(*DoubleNode).Value = 5
+	inventory, _ := player.List("Inventory")
+	inventory.Values[0].(*CompoundNode).Values["Count"].(*ByteNode).Value = 64
+	data.Values["Biomes"].(*ByteArrayNode).Values[0] = 9
+	data.Values["Heights"].(*LongArrayNode).Values[0] = 9
+	data.Values["Ints"].(*IntArrayNode).Values[0].(*IntNode).Value = 9
+	data.Delete("Player")
+
+	if !Equal(original, want.Root) {
+		t.Errorf("mutating the clone changed the original to %s", ToSNBT(original))
+	}
+}
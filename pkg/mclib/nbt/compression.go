@@ -0,0 +1,231 @@
+package nbt
+
+import (
+	"bufio"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	CompressionNone CompressionType = iota
+	CompressionGZip
+	CompressionZlib
+)
+
+type CompressionType int
+
+// maxCompressionLayers bounds how often mishandled files that have been
+// compressed more than once are unpacked.
+const maxCompressionLayers = 3
+
+func detectCompression(header []byte) CompressionType {
+	if len(header) >= 2 && header[0] == 0x1f && header[1] == 0x8b {
+		return CompressionGZip
+	}
+	if len(header) >= 2 && header[0] == 0x78 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return CompressionZlib
+	}
+	return CompressionNone
+}
+
+// decompress detects the compression of r and returns a reader for the
+// uncompressed data. Output that is itself compressed again is unpacked up to
+// maxCompressionLayers times.
+func decompress(r io.Reader, opts ReadOptions) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	for range maxCompressionLayers + 1 {
+		header, _ := br.Peek(2)
+		compression := detectCompression(header)
+		if compression == CompressionNone {
+			return br, nil
+		}
+
+		decompressor, err := newDecompressor(br, compression)
+		if err != nil {
+			return nil, err
+		}
+		if gzipReader, ok := decompressor.(*gzip.Reader); ok && opts.RejectMultistream {
+			gzipReader.Multistream(false)
+			decompressor = &singleMemberReader{gzipReader: gzipReader, src: br}
+		}
+		br = bufio.NewReader(decompressor)
+	}
+	return nil, fmt.Errorf("data is compressed more than %d times", maxCompressionLayers)
+}
+
+func newDecompressor(r io.Reader, compression CompressionType) (io.Reader, error) {
+	switch compression {
+	case CompressionNone:
+		return r, nil
+
+	case CompressionGZip:
+		gzipReader, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("open gzip reader: %w", err)
+		}
+		return gzipReader, nil
+
+	case CompressionZlib:
+		zlibReader, err := zlib.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("open zlib reader: %w", err)
+		}
+		return zlibReader, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported compression type %v", compression)
+	}
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// newCompressor returns a writer compressing into w. Closing it flushes the
+// compressed data but does not close w.
+func newCompressor(w io.Writer, compression CompressionType) (io.WriteCloser, error) {
+	switch compression {
+	case CompressionNone:
+		return nopWriteCloser{w}, nil
+	case CompressionGZip:
+		return gzip.NewWriter(w), nil
+	case CompressionZlib:
+		return zlib.NewWriter(w), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported compression type %v", compression)
+	}
+}
+
+// ConvertFile reads an NBT file of any supported compression and writes the
+// same data to dst using the target compression.
+func ConvertFile(src, dst string, targetCompression CompressionType) error {
+	f, err := ReadFromFile(src)
+	if err != nil {
+		return err
+	}
+	return writeCompressedFile(dst, f, targetCompression)
+}
+
+func writeCompressedFile(file string, f *File, compression CompressionType) error {
+	out, err := os.Create(file)
+	if err != nil {
+		return fmt.Errorf("create file: %w", err)
+	}
+	if err := writeCompressed(out, f, compression); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("close file: %w", err)
+	}
+	return nil
+}
+
+func writeCompressed(w io.Writer, f *File, compression CompressionType) error {
+	return WriteToStreamWithOptions(w, f, WriteOptions{Compression: compression})
+}
+
+// MaxInspectSize is the number of decompressed bytes after which
+// InspectCompression stops reading.
+const MaxInspectSize = 1 << 30
+
+type CompressionInfo struct {
+	Compression      CompressionType
+	CompressedSize   int64
+	DecompressedSize int64
+	// Ratio is the decompressed size divided by the compressed size.
+	Ratio float64
+	// Capped is set when decompression stopped at MaxInspectSize. Sizes and
+	// ratio then only describe the inspected prefix.
+	Capped bool
+}
+
+// InspectCompression decompresses the outermost compression layer of r without
+// parsing it, which allows spotting decompression bombs before a full read.
+func InspectCompression(r io.Reader) (*CompressionInfo, error) {
+	cr := &countingReader{r: r}
+	br := bufio.NewReader(cr)
+	header, _ := br.Peek(2)
+
+	info := &CompressionInfo{
+		Compression: detectCompression(header),
+	}
+	decompressor, err := newDecompressor(br, info.Compression)
+	if err != nil {
+		return nil, err
+	}
+	if gzipReader, ok := decompressor.(*gzip.Reader); ok {
+		// only the first member belongs to the inspected data
+		gzipReader.Multistream(false)
+	}
+
+	n, err := io.Copy(io.Discard, io.LimitReader(decompressor, MaxInspectSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("decompress: %w", err)
+	}
+	if n > MaxInspectSize {
+		n = MaxInspectSize
+		info.Capped = true
+	}
+
+	info.CompressedSize = cr.n - int64(br.Buffered())
+	info.DecompressedSize = n
+	if info.CompressedSize > 0 {
+		info.Ratio = float64(info.DecompressedSize) / float64(info.CompressedSize)
+	}
+	return info, nil
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+var errMultipleGZipMembers = errors.New("gzip stream contains more than one member")
+
+// singleMemberReader reads a single gzip member and fails instead of
+// returning io.EOF if another member follows.
+type singleMemberReader struct {
+	gzipReader *gzip.Reader
+	src        *bufio.Reader
+}
+
+func (r *singleMemberReader) Read(p []byte) (int, error) {
+	n, err := r.gzipReader.Read(p)
+	if err == io.EOF {
+		if resetErr := r.gzipReader.Reset(r.src); resetErr != io.EOF {
+			if resetErr == nil {
+				return n, errMultipleGZipMembers
+			}
+			return n, resetErr
+		}
+	}
+	return n, err
+}
+
+// drain reads r until EOF. Unlike io.Copy it never bypasses a bufio.Reader,
+// which would drop an error the reader already buffered.
+func drain(r io.Reader) error {
+	buf := make([]byte, 4096)
+	for {
+		if _, err := r.Read(buf); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package nbt
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testFile returns a small file with a few nested values.
+func testFile(t *testing.T) *File {
+	t.Helper()
+	f, err := ParseSNBT(`{Data:{LevelName:"test",Version:{Id:3465},Values:[I;1,2,3],Pos:[1.5d,2.5d]}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+// encodeFile returns the uncompressed NBT data of f.
+func encodeFile(t *testing.T, f *File) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := WriteToStream(&buf, f); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func compressBytes(t *testing.T, data []byte, compression CompressionType) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := newCompressor(&buf, compression)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestReadNestedCompression(t *testing.T) {
+	f := testFile(t)
+	raw := encodeFile(t, f)
+
+	tests := map[string][]byte{
+		"uncompressed": raw,
+		"gzip":         compressBytes(t, raw, CompressionGZip),
+		"zlib":         compressBytes(t, raw, CompressionZlib),
+		"double gzip":  compressBytes(t, compressBytes(t, raw, CompressionGZip), CompressionGZip),
+		"zlib in gzip": compressBytes(t, compressBytes(t, raw, CompressionZlib), CompressionGZip),
+		"three layers": compressBytes(t, compressBytes(t, compressBytes(t, raw, CompressionGZip), CompressionZlib), CompressionGZip),
+	}
+	for name, data := range tests {
+		readFile, err := ReadFromStream(bytes.NewReader(data))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if !Equal(readFile.Root, f.Root) {
+			t.Errorf("%s: read %v, want %v", name, readFile.Root, f.Root)
+		}
+	}
+}
+
+func TestReadFromFileDoubleGZip(t *testing.T) {
+	f := testFile(t)
+	file := filepath.Join(t.TempDir(), "level.dat")
+	data := compressBytes(t, compressBytes(t, encodeFile(t, f), CompressionGZip), CompressionGZip)
+	if err := os.WriteFile(file, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	readFile, err := ReadFromFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Equal(readFile.Root, f.Root) {
+		t.Errorf("read %v, want %v", readFile.Root, f.Root)
+	}
+}
+
+func TestReadTooManyCompressionLayers(t *testing.T) {
+	data := encodeFile(t, testFile(t))
+	for range maxCompressionLayers + 1 {
+		data = compressBytes(t, data, CompressionGZip)
+	}
+	if _, err := ReadFromStream(bytes.NewReader(data)); err == nil {
+		t.Errorf("expected error for %d compression layers", maxCompressionLayers+1)
+	}
+}
+
+func TestInspectCompression(t *testing.T) {
+	// a long run of zeros compresses very well
+	f := NewFile("")
+	f.Root.(*CompoundNode).Set("zeros", &ByteArrayNode{Values: make([]byte, 64*1024)})
+	raw := encodeFile(t, f)
+
+	for _, compression := range []CompressionType{CompressionGZip, CompressionZlib} {
+		data := compressBytes(t, raw, compression)
+		// trailing data must not be counted as compressed input
+		info, err := InspectCompression(bytes.NewReader(append(bytes.Clone(data), "trailer"...)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Compression != compression {
+			t.Errorf("compression = %v, want %v", info.Compression, compression)
+		}
+		if info.CompressedSize != int64(len(data)) || info.DecompressedSize != int64(len(raw)) {
+			t.Errorf("%v: sizes %d -> %d, want %d -> %d", compression, info.CompressedSize, info.DecompressedSize, len(data), len(raw))
+		}
+		if info.Ratio < 100 || info.Capped {
+			t.Errorf("%v: ratio %f, capped %v", compression, info.Ratio, info.Capped)
+		}
+	}
+
+	info, err := InspectCompression(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Compression != CompressionNone || info.Ratio != 1 {
+		t.Errorf("uncompressed data reported as %+v", info)
+	}
+}
+
+func TestRejectMultistream(t *testing.T) {
+	raw := encodeFile(t, testFile(t))
+	single := compressBytes(t, raw, CompressionGZip)
+	double := append(bytes.Clone(single), compressBytes(t, raw, CompressionGZip)...)
+
+	opts := ReadOptions{RejectMultistream: true}
+	if _, err := ReadFromStreamWithOptions(bytes.NewReader(single), opts); err != nil {
+		t.Errorf("single member: %v", err)
+	}
+	if _, err := ReadFromStreamWithOptions(bytes.NewReader(double), opts); !errors.Is(err, errMultipleGZipMembers) {
+		t.Errorf("two members: %v, want errMultipleGZipMembers", err)
+	}
+	// without the option, the second member is ignored
+	if _, err := ReadFromStream(bytes.NewReader(double)); err != nil {
+		t.Errorf("two members without RejectMultistream: %v", err)
+	}
+}
+
+func TestConvertFile(t *testing.T) {
+	f := testFile(t)
+	dir := t.TempDir()
+	src := filepath.Join(dir, "level.dat")
+	if err := os.WriteFile(src, compressBytes(t, encodeFile(t, f), CompressionGZip), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	steps := []CompressionType{CompressionZlib, CompressionGZip, CompressionNone, CompressionGZip}
+	for i, compression := range steps {
+		dst := filepath.Join(dir, fmt.Sprintf("step%d.dat", i))
+		if err := ConvertFile(src, dst, compression); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(dst)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if detected := detectCompression(data); detected != compression {
+			t.Errorf("step %d: file is compressed with %v, want %v", i, detected, compression)
+		}
+		readFile, err := ReadFromFile(dst)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !Equal(readFile.Root, f.Root) {
+			t.Errorf("step %d: tree changed to %v", i, ToSNBT(readFile.Root))
+		}
+		src = dst
+	}
+
+	if err := ConvertFile(filepath.Join(dir, "missing.dat"), filepath.Join(dir, "out.dat"), CompressionGZip); err == nil {
+		t.Errorf("expected error for missing source file")
+	}
+}
+
+func TestReadFromStreamDetectsCompression(t *testing.T) {
+	f := testFile(t)
+	raw := encodeFile(t, f)
+	for _, compression := range []CompressionType{CompressionNone, CompressionGZip, CompressionZlib} {
+		data := compressBytes(t, raw, compression)
+		if detected := detectCompression(data); detected != compression {
+			t.Errorf("detected %v for %v", detected, compression)
+		}
+
+		readFile, err := ReadFromStream(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("%v: %v", compression, err)
+		}
+		if !Equal(readFile.Root, f.Root) {
+			t.Errorf("%v: read %s", compression, ToSNBT(readFile.Root))
+		}
+		readFile, err = ReadFromBytes(data)
+		if err != nil {
+			t.Fatalf("%v from bytes: %v", compression, err)
+		}
+		if !Equal(readFile.Root, f.Root) {
+			t.Errorf("%v from bytes: read %s", compression, ToSNBT(readFile.Root))
+		}
+
+		// the raw reader never decompresses
+		_, err = ReadRawFromStream(bytes.NewReader(data))
+		if (err == nil) != (compression == CompressionNone) {
+			t.Errorf("%v with raw reader: %v", compression, err)
+		}
+	}
+}
+
+func TestReadFromFileDetectsCompression(t *testing.T) {
+	f := testFile(t)
+	raw := encodeFile(t, f)
+	dir := t.TempDir()
+	for _, compression := range []CompressionType{CompressionNone, CompressionGZip, CompressionZlib} {
+		path := filepath.Join(dir, fmt.Sprintf("level%d.dat", compression))
+		if err := os.WriteFile(path, compressBytes(t, raw, compression), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		readFile, err := ReadFromFile(path)
+		if err != nil {
+			t.Fatalf("%v: %v", compression, err)
+		}
+		if !Equal(readFile.Root, f.Root) {
+			t.Errorf("%v: read %s", compression, ToSNBT(readFile.Root))
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package nbt
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+// cancelReader cancels a context once more than limit bytes have been read.
+type cancelReader struct {
+	r      io.Reader
+	limit  int
+	read   int
+	cancel context.CancelFunc
+}
+
+func (r *cancelReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.read += n
+	if r.read > r.limit {
+		r.cancel()
+	}
+	return n, err
+}
+
+func TestReadFromStreamContextCancel(t *testing.T) {
+	f := NewFile("")
+	list := &ListNode{ElementType: NodeTypeCompound}
+	for i := range 100000 {
+		list.Values = append(list.Values, &CompoundNode{Values: map[string]Node{"i": &IntNode{Value: int32(i)}}})
+	}
+	f.Root.(*CompoundNode).Set("list", list)
+	data := encodeFile(t, f)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r := &cancelReader{r: bytes.NewReader(data), limit: len(data) / 2, cancel: cancel}
+	if _, err := ReadFromStreamContext(ctx, r); !errors.Is(err, context.Canceled) {
+		t.Fatalf("read with cancelled context: %v, want %v", err, context.Canceled)
+	}
+	// the parser stops soon after the cancellation instead of reading everything
+	if r.read == len(data) {
+		t.Errorf("read all %d bytes despite cancellation", r.read)
+	}
+
+	readFile, err := ReadFromStreamContext(context.Background(), bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Equal(readFile.Root, f.Root) {
+		t.Errorf("read tree differs without cancellation")
+	}
+}
@@ -0,0 +1,114 @@
+package nbt
+
+import (
+	"bytes"
+	"slices"
+)
+
+// The following helpers return the value of a node if it has the matching
+// type. They are the counterpart to the compound accessors for nodes that
+// are already at hand, e.g. list elements.
+
+func Byte(n Node) (byte, bool) {
+	if node, ok := n.(*ByteNode); ok {
+		return node.Value, true
+	}
+	return 0, false
+}
+
+func Short(n Node) (int16, bool) {
+	if node, ok := n.(*ShortNode); ok {
+		return node.Value, true
+	}
+	return 0, false
+}
+
+func Int(n Node) (int32, bool) {
+	if node, ok := n.(*IntNode); ok {
+		return node.Value, true
+	}
+	return 0, false
+}
+
+func Long(n Node) (int64, bool) {
+	if node, ok := n.(*LongNode); ok {
+		return node.Value, true
+	}
+	return 0, false
+}
+
+func F32(n Node) (float32, bool) {
+	if node, ok := n.(*FloatNode); ok {
+		return node.Value, true
+	}
+	return 0, false
+}
+
+func F64(n Node) (float64, bool) {
+	if node, ok := n.(*DoubleNode); ok {
+		return node.Value, true
+	}
+	return 0, false
+}
+
+func Str(n Node) (string, bool) {
+	if node, ok := n.(*StringNode); ok {
+		return node.Value, true
+	}
+	return "", false
+}
+
+// GoValue converts a node to the Go type matching its tag type exactly, i.e.
+// int8 for bytes, int16 for shorts, []int32 for int arrays,
+// map[string]interface{} for compounds and []interface{} for lists. Array
+// and container values are copies. Unknown tags yield their raw bytes.
+func GoValue(n Node) interface{} {
+	switch node := n.(type) {
+	case *ByteNode:
+		return int8(node.Value)
+	case *ShortNode:
+		return node.Value
+	case *IntNode:
+		return node.Value
+	case *LongNode:
+		return node.Value
+	case *FloatNode:
+		return node.Value
+	case *DoubleNode:
+		return node.Value
+	case *ByteArrayNode:
+		values := make([]int8, len(node.Values))
+		for i, val := range node.Values {
+			values[i] = int8(val)
+		}
+		return values
+	case *StringNode:
+		return node.Value
+	case *ListNode:
+		values := make([]interface{}, len(node.Values))
+		for i, childNode := range node.Values {
+			values[i] = GoValue(childNode)
+		}
+		return values
+	case *CompoundNode:
+		values := make(map[string]interface{}, len(node.Values))
+		for key, childNode := range node.Values {
+			values[key] = GoValue(childNode)
+		}
+		return values
+	case *IntArrayNode:
+		values := make([]int32, 0, len(node.Values))
+		for _, childNode := range node.Values {
+			if val, ok := Int(childNode); ok {
+				values = append(values, val)
+			}
+		}
+		return values
+	case *LongArrayNode:
+		return slices.Clone(node.Values)
+	case *UnknownNode:
+		return bytes.Clone(node.Raw)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,78 @@
+package nbt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConvertHelpers(t *testing.T) {
+	nodes := []Node{
+		&ByteNode{Value: 0xfe},
+		&ShortNode{Value: -2},
+		&IntNode{Value: -3},
+		&LongNode{Value: -4},
+		&FloatNode{Value: 1.5},
+		&DoubleNode{Value: -2.5},
+		&StringNode{Value: "text"},
+		nil,
+	}
+	// every helper must only accept the node at the same index
+	helpers := []func(Node) (interface{}, bool){
+		func(n Node) (interface{}, bool) { return Byte(n) },
+		func(n Node) (interface{}, bool) { return Short(n) },
+		func(n Node) (interface{}, bool) { return Int(n) },
+		func(n Node) (interface{}, bool) { return Long(n) },
+		func(n Node) (interface{}, bool) { return F32(n) },
+		func(n Node) (interface{}, bool) { return F64(n) },
+		func(n Node) (interface{}, bool) { return Str(n) },
+	}
+	want := []interface{}{byte(0xfe), int16(-2), int32(-3), int64(-4), float32(1.5), float64(-2.5), "text"}
+
+	for i, helper := range helpers {
+		for j, node := range nodes {
+			val, ok := helper(node)
+			if i == j {
+				if !ok || val != want[i] {
+					t.Errorf("helper %d on %T = %v, %v, want %v, true", i, node, val, ok, want[i])
+				}
+			} else if ok {
+				t.Errorf("helper %d accepts %T", i, node)
+			}
+		}
+	}
+}
+
+func TestGoValue(t *testing.T) {
+	tests := []struct {
+		node Node
+		want interface{}
+	}{
+		{&ByteNode{Value: 0xff}, int8(-1)},
+		{&ShortNode{Value: 2}, int16(2)},
+		{&IntNode{Value: 3}, int32(3)},
+		{&LongNode{Value: 4}, int64(4)},
+		{&FloatNode{Value: 1.5}, float32(1.5)},
+		{&DoubleNode{Value: 2.5}, float64(2.5)},
+		{&ByteArrayNode{Values: []byte{1, 0x80}}, []int8{1, -128}},
+		{&StringNode{Value: "a"}, "a"},
+		{&ListNode{Values: []Node{&ShortNode{Value: 1}}}, []interface{}{int16(1)}},
+		{&CompoundNode{Values: map[string]Node{"k": &LongNode{Value: 5}}}, map[string]interface{}{"k": int64(5)}},
+		{&IntArrayNode{Values: []Node{&IntNode{Value: 6}}}, []int32{6}},
+		{&LongArrayNode{Values: []int64{7}}, []int64{7}},
+		{&UnknownNode{TagType: 99, Raw: []byte{8}}, []byte{8}},
+		{nil, nil},
+	}
+	for _, test := range tests {
+		val := GoValue(test.node)
+		if reflect.TypeOf(val) != reflect.TypeOf(test.want) || !reflect.DeepEqual(val, test.want) {
+			t.Errorf("GoValue(%T) = %#v, want %#v", test.node, val, test.want)
+		}
+	}
+
+	// arrays are copied
+	array := &LongArrayNode{Values: []int64{1}}
+	GoValue(array).([]int64)[0] = 2
+	if array.Values[0] != 1 {
+		t.Errorf("GoValue aliases the long array")
+	}
+}
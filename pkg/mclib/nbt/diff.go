@@ -0,0 +1,85 @@
+package nbt
+
+import (
+	"maps"
+	"slices"
+)
+
+type ChangeKind int
+
+const (
+	ChangeAdded ChangeKind = iota
+	ChangeRemoved
+	ChangeModified
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case ChangeAdded:
+		return "added"
+	case ChangeRemoved:
+		return "removed"
+	case ChangeModified:
+		return "modified"
+	}
+	return "unknown"
+}
+
+// Change describes a difference at Path between two trees. Old is nil for
+// added nodes and New is nil for removed nodes.
+type Change struct {
+	Path string
+	Kind ChangeKind
+	Old  Node
+	New  Node
+}
+
+// Diff returns the changes turning a into b, ordered by path. Compounds are
+// compared key by key and lists of equal length element by element, so a
+// change is reported at the deepest differing node. Lists of different length
+// and nodes of different type are reported as modified as a whole.
+func Diff(a, b Node) []Change {
+	return diff("", a, b, nil)
+}
+
+func diff(path string, a, b Node, changes []Change) []Change {
+	if Equal(a, b) {
+		return changes
+	}
+
+	switch x := a.(type) {
+	case *CompoundNode:
+		if y, ok := b.(*CompoundNode); ok {
+			keys := slices.Collect(maps.Keys(x.Values))
+			for key := range y.Values {
+				if _, exists := x.Values[key]; !exists {
+					keys = append(keys, key)
+				}
+			}
+			slices.Sort(keys)
+
+			for _, key := range keys {
+				oldNode, oldExists := x.Values[key]
+				newNode, newExists := y.Values[key]
+				switch {
+				case !oldExists:
+					changes = append(changes, Change{Path: childPath(path, key), Kind: ChangeAdded, New: newNode})
+				case !newExists:
+					changes = append(changes, Change{Path: childPath(path, key), Kind: ChangeRemoved, Old: oldNode})
+				default:
+					changes = diff(childPath(path, key), oldNode, newNode, changes)
+				}
+			}
+			return changes
+		}
+
+	case *ListNode:
+		if y, ok := b.(*ListNode); ok && len(x.Values) == len(y.Values) {
+			for i := range x.Values {
+				changes = diff(indexPath(path, i), x.Values[i], y.Values[i], changes)
+			}
+			return changes
+		}
+	}
+	return append(changes, Change{Path: path, Kind: ChangeModified, Old: a, New: b})
+}
@@ -0,0 +1,55 @@
+package nbt
+
+import "testing"
+
+func TestDiff(t *testing.T) {
+	a, err := ParseSNBT(`{Data:{Version:{Id:3465,Name:"1.20.1"},Time:100L,Removed:1b}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ParseSNBT(`{Data:{Version:{Id:3700,Name:"1.20.1"},Time:100L,LevelName:"world"}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes := Diff(a.Root, b.Root)
+	want := []Change{
+		{Path: "Data.LevelName", Kind: ChangeAdded, New: &StringNode{Value: "world"}},
+		{Path: "Data.Removed", Kind: ChangeRemoved, Old: &ByteNode{Value: 1}},
+		{Path: "Data.Version.Id", Kind: ChangeModified, Old: &IntNode{Value: 3465}, New: &IntNode{Value: 3700}},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("changes = %v, want %v", changes, want)
+	}
+	for i, change := range changes {
+		if change.Path != want[i].Path || change.Kind != want[i].Kind || !Equal(change.Old, want[i].Old) || !Equal(change.New, want[i].New) {
+			t.Errorf("change %d = %s %v %v -> %v, want %s %v %v -> %v", i,
+				change.Path, change.Kind, change.Old, change.New, want[i].Path, want[i].Kind, want[i].Old, want[i].New)
+		}
+	}
+
+	if changes := Diff(a.Root, a.Root); len(changes) != 0 {
+		t.Errorf("diff of equal trees = %v", changes)
+	}
+}
+
+func TestDiffLists(t *testing.T) {
+	a, err := ParseSNBT(`{Pos:[1.0d,2.0d,3.0d],Tags:[a]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ParseSNBT(`{Pos:[1.0d,5.0d,3.0d],Tags:[a,b]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes := Diff(a.Root, b.Root)
+	// lists of equal length are compared element-wise, others as a whole
+	if len(changes) != 2 || changes[0].Path != "Pos[1]" || changes[1].Path != "Tags" {
+		t.Errorf("changes = %v", changes)
+	}
+	for _, change := range changes {
+		if change.Kind != ChangeModified {
+			t.Errorf("%s is %v, want modified", change.Path, change.Kind)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package nbt
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Dump writes the tree in the human-readable format of the NBT specification,
+// one tag per line with nested tags indented by indent, e.g.
+//
+//	TAG_Compound(""): 1 entries
+//	{
+//	  TAG_Int("DataVersion"): 3953
+//	}
+//
+// Compound children are listed in the order of CompoundNode.Keys.
+func (f *File) Dump(w io.Writer, indent string) error {
+	d := &dumper{w: w, indent: indent}
+	d.dumpNode(strconv.Quote(f.RootName), f.Root, 0)
+	return d.err
+}
+
+func (f *File) String() string {
+	var sb strings.Builder
+	f.Dump(&sb, "  ")
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// dumpString renders a single node like File.Dump without a name.
+func dumpString(node Node) string {
+	var sb strings.Builder
+	d := &dumper{w: &sb, indent: "  "}
+	d.dumpNode("None", node, 0)
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+func (n *ByteNode) String() string      { return dumpString(n) }
+func (n *ShortNode) String() string     { return dumpString(n) }
+func (n *IntNode) String() string       { return dumpString(n) }
+func (n *LongNode) String() string      { return dumpString(n) }
+func (n *FloatNode) String() string     { return dumpString(n) }
+func (n *DoubleNode) String() string    { return dumpString(n) }
+func (n *ByteArrayNode) String() string { return dumpString(n) }
+func (n *StringNode) String() string    { return dumpString(n) }
+func (n *ListNode) String() string      { return dumpString(n) }
+func (n *CompoundNode) String() string  { return dumpString(n) }
+func (n *IntArrayNode) String() string  { return dumpString(n) }
+func (n *LongArrayNode) String() string { return dumpString(n) }
+func (n *UnknownNode) String() string   { return dumpString(n) }
+
+type dumper struct {
+	w      io.Writer
+	indent string
+	// err is the first write error, after which all output is dropped.
+	err error
+}
+
+func (d *dumper) printf(depth int, format string, args ...interface{}) {
+	if d.err != nil {
+		return
+	}
+	if _, d.err = io.WriteString(d.w, strings.Repeat(d.indent, depth)); d.err != nil {
+		return
+	}
+	_, d.err = fmt.Fprintf(d.w, format+"\n", args...)
+}
+
+func (d *dumper) dumpNode(name string, node Node, depth int) {
+	if node == nil {
+		d.printf(depth, "%s: nil", name)
+		return
+	}
+	header := node.Type().String() + "(" + name + ")"
+
+	switch n := node.(type) {
+	case *ByteNode:
+		d.printf(depth, "%s: %d", header, int8(n.Value))
+	case *ShortNode:
+		d.printf(depth, "%s: %d", header, n.Value)
+	case *IntNode:
+		d.printf(depth, "%s: %d", header, n.Value)
+	case *LongNode:
+		d.printf(depth, "%s: %d", header, n.Value)
+	case *FloatNode:
+		d.printf(depth, "%s: %s", header, strconv.FormatFloat(float64(n.Value), 'g', -1, 32))
+	case *DoubleNode:
+		d.printf(depth, "%s: %s", header, strconv.FormatFloat(n.Value, 'g', -1, 64))
+	case *StringNode:
+		d.printf(depth, "%s: %q", header, n.Value)
+
+	case *ByteArrayNode:
+		values := make([]string, len(n.Values))
+		for i, val := range n.Values {
+			values[i] = strconv.Itoa(int(int8(val)))
+		}
+		d.printf(depth, "%s: [%s]", header, strings.Join(values, ", "))
+	case *IntArrayNode:
+		values := make([]string, len(n.Values))
+		for i, childNode := range n.Values {
+			if val, ok := Int(childNode); ok {
+				values[i] = strconv.Itoa(int(val))
+			} else {
+				values[i] = "?"
+			}
+		}
+		d.printf(depth, "%s: [%s]", header, strings.Join(values, ", "))
+	case *LongArrayNode:
+		values := make([]string, len(n.Values))
+		for i, val := range n.Values {
+			values[i] = strconv.FormatInt(val, 10)
+		}
+		d.printf(depth, "%s: [%s]", header, strings.Join(values, ", "))
+
+	case *ListNode:
+		d.printf(depth, "%s: %d entries", header, len(n.Values))
+		d.printf(depth, "{")
+		for _, childNode := range n.Values {
+			d.dumpNode("None", childNode, depth+1)
+		}
+		d.printf(depth, "}")
+	case *CompoundNode:
+		d.printf(depth, "%s: %d entries", header, len(n.Values))
+		d.printf(depth, "{")
+		for _, key := range n.Keys() {
+			d.dumpNode(strconv.Quote(key), n.Values[key], depth+1)
+		}
+		d.printf(depth, "}")
+
+	case *UnknownNode:
+		d.printf(depth, "%s: %d bytes of unparsed data", header, len(n.Raw))
+	default:
+		d.printf(depth, "%s: %T", header, node)
+	}
+}
@@ -0,0 +1,38 @@
+package nbt
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+func TestDumpGolden(t *testing.T) {
+	f, err := ReadFromFile("testdata/level.dat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := f.Dump(&buf, "  "); err != nil {
+		t.Fatal(err)
+	}
+
+	const golden = "testdata/level.dat.txt"
+	if *update {
+		if err := os.WriteFile(golden, buf.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("dump differs from %s:\n%s", golden, buf.String())
+	}
+	if str := f.String(); str+"\n" != string(want) {
+		t.Errorf("String() differs from %s:\n%s", golden, str)
+	}
+}
@@ -0,0 +1,73 @@
+package nbt
+
+import (
+	"bytes"
+	"math"
+	"slices"
+)
+
+// Equal reports whether a and b have the same type and value. Compounds are
+// equal if they hold equal values for the same keys, lists and arrays if they
+// hold equal elements in the same order. Floats are compared by their bit
+// pattern, so NaN equals NaN of the same representation, and 0 differs from
+// -0.
+func Equal(a, b Node) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	if a.Type() != b.Type() {
+		return false
+	}
+
+	switch x := a.(type) {
+	case *ByteNode:
+		y, ok := b.(*ByteNode)
+		return ok && x.Value == y.Value
+	case *ShortNode:
+		y, ok := b.(*ShortNode)
+		return ok && x.Value == y.Value
+	case *IntNode:
+		y, ok := b.(*IntNode)
+		return ok && x.Value == y.Value
+	case *LongNode:
+		y, ok := b.(*LongNode)
+		return ok && x.Value == y.Value
+	case *FloatNode:
+		y, ok := b.(*FloatNode)
+		return ok && math.Float32bits(x.Value) == math.Float32bits(y.Value)
+	case *DoubleNode:
+		y, ok := b.(*DoubleNode)
+		return ok && math.Float64bits(x.Value) == math.Float64bits(y.Value)
+	case *ByteArrayNode:
+		y, ok := b.(*ByteArrayNode)
+		return ok && bytes.Equal(x.Values, y.Values)
+	case *StringNode:
+		y, ok := b.(*StringNode)
+		return ok && x.Value == y.Value
+	case *ListNode:
+		y, ok := b.(*ListNode)
+		return ok && slices.EqualFunc(x.Values, y.Values, Equal)
+	case *CompoundNode:
+		y, ok := b.(*CompoundNode)
+		if !ok || len(x.Values) != len(y.Values) {
+			return false
+		}
+		for key, childNode := range x.Values {
+			otherNode, exists := y.Values[key]
+			if !exists || !Equal(childNode, otherNode) {
+				return false
+			}
+		}
+		return true
+	case *IntArrayNode:
+		y, ok := b.(*IntArrayNode)
+		return ok && slices.EqualFunc(x.Values, y.Values, Equal)
+	case *LongArrayNode:
+		y, ok := b.(*LongArrayNode)
+		return ok && slices.Equal(x.Values, y.Values)
+	case *UnknownNode:
+		y, ok := b.(*UnknownNode)
+		return ok && bytes.Equal(x.Raw, y.Raw)
+	}
+	return false
+}
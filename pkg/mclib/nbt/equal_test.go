@@ -0,0 +1,48 @@
+package nbt
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEqual(t *testing.T) {
+	a := &CompoundNode{}
+	a.Set("id", &StringNode{Value: "minecraft:stone"})
+	a.Set("Count", &ByteNode{Value: 1})
+	a.Set("Data", &ByteArrayNode{Values: []byte{1, 2, 3}})
+	b := &CompoundNode{}
+	b.Set("Data", &ByteArrayNode{Values: []byte{1, 2, 3}})
+	b.Set("Count", &ByteNode{Value: 1})
+	b.Set("id", &StringNode{Value: "minecraft:stone"})
+	if !Equal(a, b) {
+		t.Errorf("compounds with differing insertion order are not equal")
+	}
+	b.Values["Data"].(*ByteArrayNode).Values[2] = 4
+	if Equal(a, b) {
+		t.Errorf("compounds with different byte arrays are equal")
+	}
+
+	short := &ListNode{ElementType: NodeTypeInt, Values: []Node{&IntNode{Value: 1}, &IntNode{Value: 2}}}
+	long := &ListNode{ElementType: NodeTypeInt, Values: []Node{&IntNode{Value: 1}, &IntNode{Value: 2}, &IntNode{Value: 3}}}
+	if Equal(short, long) || Equal(long, short) {
+		t.Errorf("lists of different length are equal")
+	}
+	reversed := &ListNode{ElementType: NodeTypeInt, Values: []Node{&IntNode{Value: 2}, &IntNode{Value: 1}}}
+	if Equal(short, reversed) {
+		t.Errorf("lists in different order are equal")
+	}
+
+	nan := math.Float64frombits(0x7ff8000000000001)
+	if !Equal(&DoubleNode{Value: nan}, &DoubleNode{Value: nan}) {
+		t.Errorf("NaN differs from itself")
+	}
+	if Equal(&DoubleNode{Value: 0}, &DoubleNode{Value: math.Copysign(0, -1)}) {
+		t.Errorf("0 equals -0")
+	}
+	if Equal(&IntNode{Value: 1}, &LongNode{Value: 1}) {
+		t.Errorf("nodes of different type are equal")
+	}
+	if !Equal(nil, nil) || Equal(&IntNode{}, nil) {
+		t.Errorf("nil comparison is wrong")
+	}
+}
@@ -0,0 +1,63 @@
+package nbt
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// The following errors are wrapped by read errors and can be matched with
+// errors.Is.
+var (
+	// ErrEmptyInput is returned if the input holds no data at all.
+	ErrEmptyInput = errors.New("empty input")
+	// ErrUnexpectedEOF is returned if the input ends inside a tag. It also
+	// matches io.ErrUnexpectedEOF.
+	ErrUnexpectedEOF = fmt.Errorf("unexpected end of nbt data: %w", io.ErrUnexpectedEOF)
+	// ErrNotCompound is returned if the top-level tag is not a compound.
+	ErrNotCompound = errors.New("root node must be a compound")
+	// ErrUnsupportedNodeType is returned for tags of unknown types.
+	ErrUnsupportedNodeType = errors.New("unsupported node type")
+	// ErrInvalidList is returned for lists with malformed headers.
+	ErrInvalidList = errors.New("invalid list")
+	// ErrInvalidArray is returned for arrays with negative lengths.
+	ErrInvalidArray = errors.New("invalid array")
+	// ErrTooManyNodes is returned once ReadOptions.MaxTotalNodes is exceeded.
+	ErrTooManyNodes = errors.New("too many nodes")
+	// ErrDepthExceeded is returned once ReadOptions.MaxDepth is exceeded.
+	ErrDepthExceeded = errors.New("nesting too deep")
+	// ErrTooManyElements is returned for lists and arrays longer than
+	// ReadOptions.MaxElements.
+	ErrTooManyElements = errors.New("too many elements")
+)
+
+// readFull fills buf from the input and reports any premature end of the input
+// as ErrUnexpectedEOF.
+func (d *decoder) readFull(buf []byte) error {
+	if d.data != nil {
+		val, err := d.next(len(buf))
+		copy(buf, val)
+		return err
+	}
+	n, err := io.ReadFull(d.r, buf)
+	d.offset += int64(n)
+	if err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return ErrUnexpectedEOF
+		}
+		return err
+	}
+	return nil
+}
+
+// next returns the following n bytes of slice input without copying them and
+// reports a premature end of the input as ErrUnexpectedEOF.
+func (d *decoder) next(n int) ([]byte, error) {
+	if n > len(d.data)-int(d.offset) {
+		d.offset = int64(len(d.data))
+		return nil, ErrUnexpectedEOF
+	}
+	val := d.data[d.offset : int(d.offset)+n]
+	d.offset += int64(n)
+	return val, nil
+}
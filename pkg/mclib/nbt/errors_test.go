@@ -0,0 +1,157 @@
+package nbt
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestReadErrorCategories(t *testing.T) {
+	// nested builds 600 nested lists of lists
+	nested := []byte{0x0a, 0x00, 0x00, 0x09, 0x00, 0x01, 'l'}
+	for range 600 {
+		nested = append(nested, 0x09, 0x00, 0x00, 0x00, 0x01)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		opts ReadOptions
+		err  error
+	}{
+		{"empty input", nil, ReadOptions{}, ErrEmptyInput},
+		{"truncated", []byte{0x0a, 0x00, 0x00, 0x03, 0x00, 0x01, 'a', 0x00}, ReadOptions{}, ErrUnexpectedEOF},
+		{"truncated io", []byte{0x0a, 0x00}, ReadOptions{}, io.ErrUnexpectedEOF},
+		{"root not compound", []byte{0x03, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01}, ReadOptions{}, ErrNotCompound},
+		{"unsupported type", []byte{0x0a, 0x00, 0x00, 0x0d, 0x00, 0x01, 'a'}, ReadOptions{}, ErrUnsupportedNodeType},
+		{"invalid list", []byte{0x0a, 0x00, 0x00, 0x09, 0x00, 0x01, 'l', 0x01, 0xff, 0xff, 0xff, 0xff}, ReadOptions{}, ErrInvalidList},
+		{"invalid array", []byte{0x0a, 0x00, 0x00, 0x07, 0x00, 0x01, 'a', 0xff, 0xff, 0xff, 0xfe}, ReadOptions{}, ErrInvalidArray},
+		{"too many nodes", []byte{0x0a, 0x00, 0x00, 0x01, 0x00, 0x01, 'a', 0x01, 0x00}, ReadOptions{MaxTotalNodes: 1}, ErrTooManyNodes},
+		{"too deep", nested, ReadOptions{}, ErrDepthExceeded},
+		{"too many elements", []byte{0x0a, 0x00, 0x00, 0x0c, 0x00, 0x01, 'l', 0x00, 0x00, 0x00, 0x03}, ReadOptions{MaxElements: 2}, ErrTooManyElements},
+	}
+	for _, test := range tests {
+		if _, err := ReadFromBytesWithOptions(test.data, test.opts); !errors.Is(err, test.err) {
+			t.Errorf("%s from bytes: %v, want %v", test.name, err, test.err)
+		}
+		if _, err := ReadFromStreamWithOptions(bytes.NewReader(test.data), test.opts); !errors.Is(err, test.err) {
+			t.Errorf("%s from stream: %v, want %v", test.name, err, test.err)
+		}
+	}
+}
+
+// nestedCompounds returns the encoding of depth compounds nested below the
+// top-level compound.
+func nestedCompounds(depth int) []byte {
+	data := []byte{0x0a, 0x00, 0x00}
+	for range depth {
+		data = append(data, 0x0a, 0x00, 0x01, 'a')
+	}
+	return append(data, bytes.Repeat([]byte{0x00}, depth+1)...)
+}
+
+func TestMaxDepth(t *testing.T) {
+	_, err := ReadFromBytes(nestedCompounds(600))
+	if !errors.Is(err, ErrDepthExceeded) || !strings.Contains(err.Error(), "max nesting depth 512 exceeded") {
+		t.Errorf("600 levels: %v", err)
+	}
+	if _, err := ReadFromBytes(nestedCompounds(DefaultMaxDepth)); err != nil {
+		t.Errorf("%d levels: %v", DefaultMaxDepth, err)
+	}
+
+	f, err := ReadFromStreamWithOptions(bytes.NewReader(nestedCompounds(600)), ReadOptions{MaxDepth: 600})
+	if err != nil {
+		t.Fatalf("600 levels with MaxDepth 600: %v", err)
+	}
+	if count := Stats(f.Root)[NodeTypeCompound]; count != 601 {
+		t.Errorf("read %d compounds, want 601", count)
+	}
+	_, err = ReadFromBytesWithOptions(nestedCompounds(10), ReadOptions{MaxDepth: 9})
+	if !errors.Is(err, ErrDepthExceeded) || !strings.Contains(err.Error(), "max nesting depth 9 exceeded") {
+		t.Errorf("10 levels with MaxDepth 9: %v", err)
+	}
+}
+
+func TestHugeLengthPrefix(t *testing.T) {
+	// {l:...} claiming 0x7FFFFFFF elements without any payload
+	header := []byte{0x0a, 0x00, 0x00}
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"byte list", append(bytes.Clone(header), 0x09, 0x00, 0x01, 'l', 0x01, 0x7f, 0xff, 0xff, 0xff)},
+		{"compound list", append(bytes.Clone(header), 0x09, 0x00, 0x01, 'l', 0x0a, 0x7f, 0xff, 0xff, 0xff)},
+		{"byte array", append(bytes.Clone(header), 0x07, 0x00, 0x01, 'l', 0x7f, 0xff, 0xff, 0xff)},
+		{"int array", append(bytes.Clone(header), 0x0b, 0x00, 0x01, 'l', 0x7f, 0xff, 0xff, 0xff)},
+		{"long array", append(bytes.Clone(header), 0x0c, 0x00, 0x01, 'l', 0x7f, 0xff, 0xff, 0xff)},
+	}
+	for _, test := range tests {
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		_, err := ReadRawFromStream(bytes.NewReader(test.data))
+		runtime.ReadMemStats(&after)
+		if !errors.Is(err, ErrUnexpectedEOF) {
+			t.Errorf("%s: %v, want %v", test.name, err, ErrUnexpectedEOF)
+		}
+		if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 16<<20 {
+			t.Errorf("%s: allocated %d bytes for an empty body", test.name, allocated)
+		}
+
+		_, err = ReadRawFromStreamWithOptions(bytes.NewReader(test.data), ReadOptions{MaxElements: 1 << 20})
+		if !errors.Is(err, ErrTooManyElements) {
+			t.Errorf("%s with MaxElements: %v, want %v", test.name, err, ErrTooManyElements)
+		}
+	}
+}
+
+func TestReadBogusNodeType(t *testing.T) {
+	// {a:1b,<0x40>...}
+	data := []byte{0x0a, 0x00, 0x00, 0x01, 0x00, 0x01, 'a', 0x01, 0x40, 0x00, 0x01, 'b'}
+	for _, read := range []func() error{
+		func() error { _, err := ReadFromBytes(data); return err },
+		func() error { _, err := ReadRawFromStream(bytes.NewReader(data)); return err },
+	} {
+		err := read()
+		if !errors.Is(err, ErrUnsupportedNodeType) {
+			t.Fatalf("read bogus type: %v, want %v", err, ErrUnsupportedNodeType)
+		}
+		if !strings.Contains(err.Error(), "at offset 8") {
+			t.Errorf("error %q does not name offset 8", err)
+		}
+	}
+
+	// a bogus element type of a list
+	data = []byte{0x0a, 0x00, 0x00, 0x09, 0x00, 0x01, 'l', 0xff, 0x00, 0x00, 0x00, 0x00, 0x00}
+	if _, err := ReadFromBytes(data); !errors.Is(err, ErrUnsupportedNodeType) || !strings.Contains(err.Error(), "at offset 7") {
+		t.Errorf("read bogus list type: %v", err)
+	}
+}
+
+func TestTruncatedOffset(t *testing.T) {
+	data := readGZipFile(t, "testdata/level.dat")
+	offsetPattern := regexp.MustCompile(`^read nbt data: at offset (\d+): `)
+	for cut := 1; cut < len(data); cut++ {
+		for _, read := range []func() error{
+			func() error { _, err := ReadFromBytes(data[:cut]); return err },
+			func() error { _, err := ReadRawFromStream(bytes.NewReader(data[:cut])); return err },
+		} {
+			err := read()
+			if !errors.Is(err, ErrUnexpectedEOF) {
+				t.Fatalf("truncated at %d: %v, want %v", cut, err, ErrUnexpectedEOF)
+			}
+			match := offsetPattern.FindStringSubmatch(err.Error())
+			if match == nil {
+				t.Fatalf("truncated at %d: error %q has no offset", cut, err)
+			}
+			// a fixed-width value that is cut off may not count as read
+			if offset, _ := strconv.Atoi(match[1]); offset > cut || offset < cut-8 {
+				t.Fatalf("truncated at %d: error at offset %d", cut, offset)
+			}
+		}
+	}
+}
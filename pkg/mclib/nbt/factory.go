@@ -0,0 +1,67 @@
+package nbt
+
+// NodeFactory creates nodes while parsing, which allows building a custom
+// representation of the data in a single pass. Container values are passed
+// once all of their children have been created, and compounds also receive
+// their keys in the order they were read. A node returned for a compound must
+// report NodeTypeCompound, and so on, for readers relying on Type.
+type NodeFactory interface {
+	NewByte(val byte) Node
+	NewShort(val int16) Node
+	NewInt(val int32) Node
+	NewLong(val int64) Node
+	NewFloat(val float32) Node
+	NewDouble(val float64) Node
+	NewByteArray(values []byte) Node
+	NewString(val string) Node
+	NewList(elemType NodeType, values []Node) Node
+	NewCompound(keys []string, values map[string]Node) Node
+	NewIntArray(values []int32) Node
+	NewLongArray(values []int64) Node
+}
+
+// DefaultNodeFactory creates the node types of this package.
+var DefaultNodeFactory NodeFactory = defaultNodeFactory{}
+
+type defaultNodeFactory struct{}
+
+func (defaultNodeFactory) NewByte(val byte) Node      { return &ByteNode{Value: val} }
+func (defaultNodeFactory) NewShort(val int16) Node    { return &ShortNode{Value: val} }
+func (defaultNodeFactory) NewInt(val int32) Node      { return &IntNode{Value: val} }
+func (defaultNodeFactory) NewLong(val int64) Node     { return &LongNode{Value: val} }
+func (defaultNodeFactory) NewFloat(val float32) Node  { return &FloatNode{Value: val} }
+func (defaultNodeFactory) NewDouble(val float64) Node { return &DoubleNode{Value: val} }
+func (defaultNodeFactory) NewString(val string) Node  { return &StringNode{Value: val} }
+
+func (defaultNodeFactory) NewByteArray(values []byte) Node {
+	return &ByteArrayNode{Values: values}
+}
+
+func (defaultNodeFactory) NewList(elemType NodeType, values []Node) Node {
+	return &ListNode{ElementType: elemType, Values: values}
+}
+
+func (defaultNodeFactory) NewCompound(keys []string, values map[string]Node) Node {
+	return &CompoundNode{Values: values, keyOrder: keys}
+}
+
+func (defaultNodeFactory) NewIntArray(values []int32) Node {
+	node := &IntArrayNode{
+		Values: make([]Node, 0, len(values)),
+	}
+	for _, val := range values {
+		node.Values = append(node.Values, &IntNode{Value: val})
+	}
+	return node
+}
+
+func (defaultNodeFactory) NewLongArray(values []int64) Node {
+	return &LongArrayNode{Values: values}
+}
+
+func (d *decoder) nodes() NodeFactory {
+	if d.opts.NodeFactory != nil {
+		return d.opts.NodeFactory
+	}
+	return DefaultNodeFactory
+}
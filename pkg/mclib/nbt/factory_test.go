@@ -0,0 +1,53 @@
+package nbt
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// upperFactory creates the default nodes, but upper-cases strings and counts
+// the created compounds.
+type upperFactory struct {
+	defaultNodeFactory
+	compounds int
+}
+
+func (f *upperFactory) NewString(val string) Node {
+	return &StringNode{Value: strings.ToUpper(val)}
+}
+
+func (f *upperFactory) NewCompound(keys []string, values map[string]Node) Node {
+	f.compounds++
+	return f.defaultNodeFactory.NewCompound(keys, values)
+}
+
+func TestNodeFactory(t *testing.T) {
+	data := encodeFile(t, testFile(t))
+
+	factory := &upperFactory{}
+	f, err := ReadFromBytesWithOptions(data, ReadOptions{NodeFactory: factory})
+	if err != nil {
+		t.Fatal(err)
+	}
+	level, _ := f.Root.(*CompoundNode).Compound("Data")
+	if name, _ := level.Str("LevelName"); name != "TEST" {
+		t.Errorf("LevelName = %q, want TEST", name)
+	}
+	// the root, Data and Version
+	if factory.compounds != 3 {
+		t.Errorf("created %d compounds, want 3", factory.compounds)
+	}
+	want := []string{"LevelName", "Version", "Values", "Pos"}
+	if keys := level.Keys(); !slices.Equal(keys, want) {
+		t.Errorf("keys = %v, want read order %v", keys, want)
+	}
+
+	f, err = ReadFromBytesWithOptions(data, ReadOptions{NodeFactory: DefaultNodeFactory})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Equal(f.Root, testFile(t).Root) {
+		t.Errorf("default factory read %s", ToSNBT(f.Root))
+	}
+}
@@ -0,0 +1,176 @@
+package nbt
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+)
+
+// FilterStream copies the uncompressed NBT data of r to w, keeping only the
+// nodes whose path keep accepts. Paths consist of the compound keys and list
+// indices leading to a node, starting below the top-level compound. Accepted
+// nodes are copied with all their children, and the containers leading to
+// them are retained. Empty containers that are not accepted are dropped.
+//
+// Compounds are filtered as they are read. The required element count of
+// lists is only known after filtering, so lists that are not accepted as a
+// whole are held in memory while their elements are filtered. Kept list
+// elements move up to close the gaps of dropped ones.
+func FilterStream(r io.Reader, w io.Writer, keep func(path []string) bool) error {
+	f := &streamFilter{
+		sr:   NewStreamReader(r),
+		sw:   NewStreamWriter(w),
+		keep: keep,
+	}
+	if err := f.filterRoot(); err != nil {
+		return fmt.Errorf("filter nbt data: %w", err)
+	}
+	return nil
+}
+
+type streamFilter struct {
+	sr   *StreamReader
+	sw   *StreamWriter
+	keep func(path []string) bool
+	// pending holds the names of opened compounds that are only written once
+	// they turn out to contain a kept node.
+	pending []string
+}
+
+func (f *streamFilter) filterRoot() error {
+	ev, err := f.sr.Next()
+	if err != nil {
+		return err
+	}
+	// the top-level compound is always written
+	if err := f.sw.StartCompound(ev.Name); err != nil {
+		return err
+	}
+	if err := f.filterCompound(nil); err != nil {
+		return err
+	}
+	return f.sw.End()
+}
+
+func (f *streamFilter) filterCompound(path []string) error {
+	for {
+		ev, err := f.sr.Next()
+		if err != nil {
+			return err
+		}
+		if ev.Kind == EventEnd {
+			return nil
+		}
+
+		childPath := append(slices.Clip(path), ev.Name)
+		if f.keep(childPath) {
+			if err := f.flush(); err != nil {
+				return err
+			}
+			if err := f.copyEvent(ev); err != nil {
+				return err
+			}
+			continue
+		}
+
+		switch ev.Kind {
+		case EventStartCompound:
+			f.pending = append(f.pending, ev.Name)
+			depth := len(f.pending)
+			if err := f.filterCompound(childPath); err != nil {
+				return err
+			}
+			if len(f.pending) >= depth {
+				// nothing kept, the compound has never been written
+				f.pending = f.pending[:depth-1]
+			} else if err := f.sw.End(); err != nil {
+				return err
+			}
+
+		case EventStartList:
+			nodes, err := f.sr.readRemaining()
+			if err != nil {
+				return err
+			}
+			if listNode := filterNode(childPath, &ListNode{ElementType: ev.ElemType, Values: nodes}, f.keep); listNode != nil {
+				if err := f.flush(); err != nil {
+					return err
+				}
+				if err := f.sw.WriteValue(ev.Name, listNode); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// flush writes all pending compounds.
+func (f *streamFilter) flush() error {
+	for _, name := range f.pending {
+		if err := f.sw.StartCompound(name); err != nil {
+			return err
+		}
+	}
+	f.pending = f.pending[:0]
+	return nil
+}
+
+// copyEvent writes the node opened by ev including all of its children.
+func (f *streamFilter) copyEvent(ev Event) error {
+	for depth := 0; ; {
+		var err error
+		switch ev.Kind {
+		case EventStartCompound:
+			err = f.sw.StartCompound(ev.Name)
+			depth++
+		case EventStartList:
+			err = f.sw.StartList(ev.Name, ev.ElemType, ev.Len)
+			depth++
+		case EventValue:
+			err = f.sw.WriteValue(ev.Name, ev.Value)
+		case EventEnd:
+			err = f.sw.End()
+			depth--
+		}
+		if err != nil || depth == 0 {
+			return err
+		}
+
+		if ev, err = f.sr.Next(); err != nil {
+			return err
+		}
+	}
+}
+
+// filterNode returns the part of an in-memory node that FilterStream would
+// keep, or nil if nothing is kept.
+func filterNode(path []string, node Node, keep func(path []string) bool) Node {
+	if keep(path) {
+		return node
+	}
+
+	switch n := node.(type) {
+	case *CompoundNode:
+		filtered := &CompoundNode{Values: make(map[string]Node)}
+		for _, key := range n.Keys() {
+			if childNode := filterNode(append(slices.Clip(path), key), n.Values[key], keep); childNode != nil {
+				filtered.Set(key, childNode)
+			}
+		}
+		if len(filtered.Values) > 0 {
+			return filtered
+		}
+	case *ListNode:
+		filtered := &ListNode{ElementType: n.ElementType}
+		for i, childNode := range n.Values {
+			if childNode := filterNode(append(slices.Clip(path), strconv.Itoa(i)), childNode, keep); childNode != nil {
+				filtered.Values = append(filtered.Values, childNode)
+			}
+		}
+		if len(filtered.Values) > 0 {
+			return filtered
+		}
+	}
+	return nil
+}
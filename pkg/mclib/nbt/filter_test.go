@@ -0,0 +1,60 @@
+package nbt
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFilterStreamGameRules(t *testing.T) {
+	f, err := ParseSNBT(`{Data:{LevelName:"x",GameRules:{doDaylightCycle:"true",keepInventory:"false"},Player:{Inventory:[{id:"a"}]},Empty:{}},Other:1b}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.RootName = "root"
+
+	var out bytes.Buffer
+	err = FilterStream(bytes.NewReader(encodeFile(t, f)), &out, func(path []string) bool {
+		return len(path) >= 3 && path[0] == "Data" && path[1] == "GameRules"
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	filtered, err := ReadFromBytes(out.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := ParseSNBT(`{Data:{GameRules:{doDaylightCycle:"true",keepInventory:"false"}}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filtered.RootName != "root" || !Equal(filtered.Root, want.Root) {
+		t.Errorf("filtered %q: %s, want %s", filtered.RootName, ToSNBT(filtered.Root), ToSNBT(want.Root))
+	}
+}
+
+func TestFilterStreamListElements(t *testing.T) {
+	f, err := ParseSNBT(`{Inventory:[{id:"a",Count:1b},{id:"b",Count:2b,tag:{x:1}},{id:"c",Count:3b}]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	err = FilterStream(bytes.NewReader(encodeFile(t, f)), &out, func(path []string) bool {
+		// keep the id of every item and the tag of the second one
+		return (len(path) == 3 && path[2] == "id") || (len(path) >= 3 && path[1] == "1" && path[2] == "tag")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	filtered, err := ReadFromBytes(out.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := ParseSNBT(`{Inventory:[{id:"a"},{id:"b",tag:{x:1}},{id:"c"}]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Equal(filtered.Root, want.Root) {
+		t.Errorf("filtered %s, want %s", ToSNBT(filtered.Root), ToSNBT(want.Root))
+	}
+}
@@ -0,0 +1,44 @@
+package nbt
+
+import "strings"
+
+// Match is a node found by FindAll along with its path like
+// "Level.Entities[3].id".
+type Match struct {
+	Path string
+	Node Node
+}
+
+// FindAll returns all compound children below root whose key equals name, at
+// any depth and in the order visited by Walk.
+func FindAll(root Node, name string) []Match {
+	return findAll(root, func(key string) bool { return key == name })
+}
+
+// FindAllFold is like FindAll, but compares keys case-insensitively.
+func FindAllFold(root Node, name string) []Match {
+	return findAll(root, func(key string) bool { return strings.EqualFold(key, name) })
+}
+
+func findAll(root Node, matchKey func(key string) bool) []Match {
+	var matches []Match
+	var find func(path string, node Node)
+	find = func(path string, node Node) {
+		switch n := node.(type) {
+		case *CompoundNode:
+			for _, key := range n.SortedKeys() {
+				keyPath := childPath(path, key)
+				if matchKey(key) {
+					matches = append(matches, Match{Path: keyPath, Node: n.Values[key]})
+				}
+				find(keyPath, n.Values[key])
+			}
+		case *ListNode:
+			for i, childNode := range n.Values {
+				find(indexPath(path, i), childNode)
+			}
+		}
+	}
+	find("", root)
+	return matches
+}
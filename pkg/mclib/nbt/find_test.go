@@ -0,0 +1,50 @@
+package nbt
+
+import (
+	"slices"
+	"testing"
+)
+
+func matchPaths(matches []Match) []string {
+	paths := make([]string, 0, len(matches))
+	for _, match := range matches {
+		paths = append(paths, match.Path)
+	}
+	return paths
+}
+
+func TestFindAll(t *testing.T) {
+	f, err := ParseSNBT(`{Data:{Player:{Inventory:[{Slot:0b,id:"minecraft:stone"},{Slot:1b,id:"minecraft:shulker_box",tag:{BlockEntityTag:{Items:[{Slot:0b,id:"minecraft:diamond"}]}}}],` +
+		`EnderItems:[{Slot:0b,id:"minecraft:apple"}],ID:"upper"}}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	matches := FindAll(f.Root, "id")
+	want := []string{
+		"Data.Player.EnderItems[0].id",
+		"Data.Player.Inventory[0].id",
+		"Data.Player.Inventory[1].id",
+		"Data.Player.Inventory[1].tag.BlockEntityTag.Items[0].id",
+	}
+	if paths := matchPaths(matches); !slices.Equal(paths, want) {
+		t.Errorf("paths = %q\nwant %q", paths, want)
+	}
+	if id, _ := Str(matches[3].Node); id != "minecraft:diamond" {
+		t.Errorf("node of %s = %v", matches[3].Path, matches[3].Node)
+	}
+
+	want = []string{
+		"Data.Player.EnderItems[0].id",
+		"Data.Player.ID",
+		"Data.Player.Inventory[0].id",
+		"Data.Player.Inventory[1].id",
+		"Data.Player.Inventory[1].tag.BlockEntityTag.Items[0].id",
+	}
+	if paths := matchPaths(FindAllFold(f.Root, "Id")); !slices.Equal(paths, want) {
+		t.Errorf("case-insensitive paths = %q\nwant %q", paths, want)
+	}
+	if matches := FindAll(f.Root, "missing"); len(matches) != 0 {
+		t.Errorf("matches of missing key = %v", matches)
+	}
+}
@@ -0,0 +1,174 @@
+package nbt
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+)
+
+// ContentHash returns a hash over the type and value of a node. Compound
+// keys are hashed in sorted order, so nodes with equal content always yield
+// the same hash.
+func ContentHash(n Node) ([sha256.Size]byte, error) {
+	if n == nil {
+		return [sha256.Size]byte{}, fmt.Errorf("hash nil node")
+	}
+
+	h := sha256.New()
+	e := &encoder{w: h, order: binary.BigEndian, sortKeys: true}
+	if err := e.writeRawByte(byte(n.Type())); err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	if err := e.writeNode(n); err != nil {
+		return [sha256.Size]byte{}, fmt.Errorf("hash node: %w", err)
+	}
+
+	var sum [sha256.Size]byte
+	h.Sum(sum[:0])
+	return sum, nil
+}
+
+// HashFile returns the content hash of the top-level compound of an NBT file
+// of any supported compression, which equals the ContentHash of its tree.
+// Files holding the same tree therefore hash identically regardless of
+// compression and key order.
+//
+// The file is decoded in a single pass without building a tree. Values are
+// encoded as soon as they are read, and only the encoding of the children of
+// each open compound is kept until their keys can be sorted.
+func HashFile(path string) ([sha256.Size]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return [sha256.Size]byte{}, fmt.Errorf("read file: %w", err)
+	}
+	defer file.Close()
+
+	r, err := decompress(file, ReadOptions{})
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	d := &decoder{r: r, order: binary.BigEndian}
+	payload, err := d.readRootPayload()
+	if err != nil {
+		return [sha256.Size]byte{}, fmt.Errorf("read nbt data: %w", d.offsetError(err))
+	}
+
+	h := sha256.New()
+	h.Write([]byte{byte(NodeTypeCompound)})
+	h.Write(payload)
+	var sum [sha256.Size]byte
+	h.Sum(sum[:0])
+	return sum, nil
+}
+
+// readRootPayload reads the top-level compound and returns its payload as
+// ContentHash encodes it.
+func (d *decoder) readRootPayload() ([]byte, error) {
+	nodeType, err := d.readRawNodeType()
+	if err != nil {
+		if err == ErrUnexpectedEOF {
+			return nil, ErrEmptyInput
+		}
+		return nil, err
+	}
+	if nodeType != NodeTypeCompound {
+		return nil, fmt.Errorf("%w, got type %v", ErrNotCompound, nodeType)
+	}
+	if _, err := d.readRawString(); err != nil {
+		return nil, err
+	}
+	return d.readSortedPayload(NodeTypeCompound)
+}
+
+// readSortedPayload reads a node and returns its encoded payload with the
+// children of all compounds in sorted key order.
+func (d *decoder) readSortedPayload(nodeType NodeType) ([]byte, error) {
+	var buf bytes.Buffer
+	e := &encoder{w: &buf, order: binary.BigEndian}
+	switch nodeType {
+	case NodeTypeList:
+		if err := d.enterContainer(); err != nil {
+			return nil, err
+		}
+		defer d.leaveContainer()
+
+		elemType, err := d.readRawNodeType()
+		if err != nil {
+			return nil, err
+		}
+		length, err := d.readRawInt()
+		if err != nil {
+			return nil, err
+		}
+		if !IsValidNodeType(elemType) {
+			return nil, fmt.Errorf("%w: element type %v", ErrInvalidList, elemType)
+		}
+		if elemType == NodeTypeEnd && length > 0 {
+			return nil, fmt.Errorf("%w: %d elements of end type", ErrInvalidList, length)
+		}
+		if length < 0 {
+			return nil, fmt.Errorf("%w: negative length %d", ErrInvalidList, length)
+		}
+		e.writeRawByte(byte(elemType))
+		e.writeRawInt(length)
+		for i := range int(length) {
+			payload, err := d.readSortedPayload(elemType)
+			if err != nil {
+				return nil, fmt.Errorf("read list index %d: %w", i, err)
+			}
+			buf.Write(payload)
+		}
+
+	case NodeTypeCompound:
+		if err := d.enterContainer(); err != nil {
+			return nil, err
+		}
+		defer d.leaveContainer()
+
+		children := make(map[string][]byte)
+		for {
+			childType, err := d.readRawNodeType()
+			if err != nil {
+				return nil, err
+			}
+			if childType == NodeTypeEnd {
+				break
+			}
+			name, err := d.readRawString()
+			if err != nil {
+				return nil, err
+			}
+			payload, err := d.readSortedPayload(childType)
+			if err != nil {
+				return nil, fmt.Errorf("read compound child %q: %w", name, err)
+			}
+
+			var child bytes.Buffer
+			ce := &encoder{w: &child, order: binary.BigEndian}
+			ce.writeRawByte(byte(childType))
+			if err := ce.writeRawString(name); err != nil {
+				return nil, err
+			}
+			child.Write(payload)
+			children[name] = child.Bytes()
+		}
+		for _, name := range slices.Sorted(maps.Keys(children)) {
+			buf.Write(children[name])
+		}
+		e.writeRawByte(byte(NodeTypeEnd))
+
+	default:
+		node, err := d.readNodeOfType(nodeType)
+		if err != nil {
+			return nil, err
+		}
+		if err := e.writeNode(node); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,97 @@
+package nbt
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestContentHashIgnoresKeyOrder(t *testing.T) {
+	a := &CompoundNode{}
+	a.Set("id", &StringNode{Value: "minecraft:stone"})
+	a.Set("Count", &ByteNode{Value: 1})
+	b := &CompoundNode{}
+	b.Set("Count", &ByteNode{Value: 1})
+	b.Set("id", &StringNode{Value: "minecraft:stone"})
+
+	hashA, err := ContentHash(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hashB, err := ContentHash(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hashA != hashB {
+		t.Errorf("hashes of equal compounds differ: %x != %x", hashA, hashB)
+	}
+
+	b.Set("Count", &ByteNode{Value: 2})
+	hashB, err = ContentHash(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hashA == hashB {
+		t.Errorf("hashes of different compounds are equal")
+	}
+}
+
+func TestHashFileCompressionVariants(t *testing.T) {
+	f := testFile(t)
+	raw := encodeFile(t, f)
+	want, err := ContentHash(f.Root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	for _, compression := range []CompressionType{CompressionNone, CompressionGZip, CompressionZlib} {
+		path := filepath.Join(dir, fmt.Sprintf("file%d.dat", compression))
+		if err := os.WriteFile(path, compressBytes(t, raw, compression), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		hash, err := HashFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hash != want {
+			t.Errorf("hash of compression %v differs: %x != %x", compression, hash, want)
+		}
+	}
+
+	if _, err := HashFile(filepath.Join(dir, "missing.dat")); err == nil {
+		t.Errorf("expected error for missing file")
+	}
+}
+
+func TestHashFileMatchesContentHash(t *testing.T) {
+	f, err := ParseSNBT(`{z:[{b:1b,a:[L;3L,-4L]},{}],y:{d:[[1s],[]],c:"text"},x:[B;1b,2b],w:1.5f}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := ContentHash(f.Root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "nested.dat")
+	if err := os.WriteFile(path, encodeFile(t, f), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := HashFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash != want {
+		t.Errorf("HashFile() = %x, want %x", hash, want)
+	}
+
+	if err := os.WriteFile(path, []byte{byte(NodeTypeInt), 0, 0, 0, 0, 0, 1}, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := HashFile(path); !errors.Is(err, ErrNotCompound) {
+		t.Errorf("hash of int root: %v, want %v", err, ErrNotCompound)
+	}
+}
@@ -0,0 +1,51 @@
+package nbt
+
+// itemDataKeys hold the custom data of items, "tag" before 1.20.5 and
+// "components" since then.
+var itemDataKeys = []string{"tag", "components"}
+
+// StripItemTags removes the custom data of all items and block entities in
+// the file, leaving ids and counts intact. It returns the number of removed
+// subtrees.
+func StripItemTags(f *File) int {
+	rootNode, ok := f.rootCompound()
+	if !ok {
+		return 0
+	}
+
+	removed := 0
+	Walk(rootNode, func(_ string, node Node) error {
+		compoundNode, ok := node.(*CompoundNode)
+		if !ok || !(isItem(compoundNode) || isBlockEntity(compoundNode)) {
+			return nil
+		}
+		for _, key := range itemDataKeys {
+			if compoundNode.Delete(key) {
+				removed++
+			}
+		}
+		return nil
+	})
+	return removed
+}
+
+func isItem(n *CompoundNode) bool {
+	if _, ok := Str(n.Values["id"]); !ok {
+		return false
+	}
+	_, hasCount := Byte(n.Values["Count"])
+	_, hasNewCount := Int(n.Values["count"])
+	return hasCount || hasNewCount
+}
+
+func isBlockEntity(n *CompoundNode) bool {
+	if _, ok := Str(n.Values["id"]); !ok {
+		return false
+	}
+	for _, key := range []string{"x", "y", "z"} {
+		if _, ok := Int(n.Values[key]); !ok {
+			return false
+		}
+	}
+	return true
+}
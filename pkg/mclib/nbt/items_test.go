@@ -0,0 +1,45 @@
+package nbt
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestStripItemTags(t *testing.T) {
+	f, err := ParseSNBT(`{Inventory:[{id:"minecraft:diamond_sword",Count:1b,tag:{Damage:5}},{id:"minecraft:stone",count:64,components:{"minecraft:custom_name":"x"}}],Chest:{id:"minecraft:chest",x:1,y:2,z:3,tag:{Lock:"key"}},tag:{keep:1b}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed := StripItemTags(f); removed != 3 {
+		t.Errorf("removed %d subtrees, want 3", removed)
+	}
+
+	root := f.Root.(*CompoundNode)
+	if _, ok := root.Values["tag"]; !ok {
+		t.Errorf("tag of the root compound should be kept")
+	}
+	items, _ := root.List("Inventory")
+	for i, item := range items.Values {
+		compoundNode := item.(*CompoundNode)
+		if _, ok := compoundNode.Values["id"]; !ok {
+			t.Errorf("item %d lost its id", i)
+		}
+		if _, ok := compoundNode.Values["tag"]; ok {
+			t.Errorf("item %d still has a tag", i)
+		}
+		if _, ok := compoundNode.Values["components"]; ok {
+			t.Errorf("item %d still has components", i)
+		}
+	}
+	if count, _ := Byte(items.Values[0].(*CompoundNode).Values["Count"]); count != 1 {
+		t.Errorf("Count = %d, want 1", count)
+	}
+
+	// keys set again after stripping are ordered after the remaining ones
+	sword := items.Values[0].(*CompoundNode)
+	sword.Set("Slot", &ByteNode{})
+	sword.Set("tag", &CompoundNode{})
+	if keys, want := sword.Keys(), []string{"id", "Count", "Slot", "tag"}; !slices.Equal(keys, want) {
+		t.Errorf("Keys() = %v, want %v", keys, want)
+	}
+}
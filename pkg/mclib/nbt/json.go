@@ -0,0 +1,232 @@
+package nbt
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+var jsonTypeNames = map[NodeType]string{
+	NodeTypeByte:      "byte",
+	NodeTypeShort:     "short",
+	NodeTypeInt:       "int",
+	NodeTypeLong:      "long",
+	NodeTypeFloat:     "float",
+	NodeTypeDouble:    "double",
+	NodeTypeByteArray: "byte_array",
+	NodeTypeString:    "string",
+	NodeTypeList:      "list",
+	NodeTypeCompound:  "compound",
+	NodeTypeIntArray:  "int_array",
+	NodeTypeLongArray: "long_array",
+}
+
+type jsonNode struct {
+	Type string `json:"type"`
+	// ElementType is the declared element type of lists, which is kept for
+	// empty lists.
+	ElementType string      `json:"element_type,omitempty"`
+	Value       interface{} `json:"value"`
+}
+
+type jsonFile struct {
+	Name string `json:"name"`
+	jsonNode
+}
+
+// MarshalJSON renders the file as JSON that keeps the type of every node, e.g.
+// {"type":"int","value":42}, so it can be read back by UnmarshalNBTJSON. The
+// top-level object additionally holds the name of the root compound. Compound
+// keys are sorted, lists hold typed elements along with their "element_type",
+// which is kept for empty lists, and arrays hold plain numbers. NaN and
+// infinite floats have no JSON representation and cause an error.
+func (f *File) MarshalJSON() ([]byte, error) {
+	root, err := toJSONNode(f.Root)
+	if err != nil {
+		return nil, fmt.Errorf("marshal nbt data: %w", err)
+	}
+	return json.Marshal(jsonFile{Name: f.RootName, jsonNode: root})
+}
+
+// MarshalCompactJSON renders the root compound as plain JSON values without
+// type information, using the types of GoValue. The result is easier to
+// consume, but cannot be converted back without losing the integer widths.
+func (f *File) MarshalCompactJSON() ([]byte, error) {
+	return json.Marshal(GoValue(f.Root))
+}
+
+func toJSONNode(node Node) (jsonNode, error) {
+	if node == nil {
+		return jsonNode{}, fmt.Errorf("nil node")
+	}
+	typeName, ok := jsonTypeNames[node.Type()]
+	if !ok {
+		return jsonNode{}, fmt.Errorf("node type %v cannot be represented as JSON", node.Type())
+	}
+
+	switch n := node.(type) {
+	case *ListNode:
+		values := make([]jsonNode, 0, len(n.Values))
+		for i, childNode := range n.Values {
+			value, err := toJSONNode(childNode)
+			if err != nil {
+				return jsonNode{}, fmt.Errorf("list index %d: %w", i, err)
+			}
+			values = append(values, value)
+		}
+		elemType := n.ElementType
+		if len(n.Values) > 0 {
+			elemType = n.Values[0].Type()
+		}
+		return jsonNode{Type: typeName, ElementType: jsonTypeNames[elemType], Value: values}, nil
+
+	case *CompoundNode:
+		values := make(map[string]jsonNode, len(n.Values))
+		for key, childNode := range n.Values {
+			value, err := toJSONNode(childNode)
+			if err != nil {
+				return jsonNode{}, fmt.Errorf("compound child %q: %w", key, err)
+			}
+			values[key] = value
+		}
+		return jsonNode{Type: typeName, Value: values}, nil
+
+	case *UnknownNode:
+		return jsonNode{}, fmt.Errorf("unknown tag cannot be represented as JSON")
+	}
+	return jsonNode{Type: typeName, Value: GoValue(node)}, nil
+}
+
+type jsonInputNode struct {
+	Type        string          `json:"type"`
+	ElementType string          `json:"element_type"`
+	Value       json.RawMessage `json:"value"`
+}
+
+type jsonInputFile struct {
+	Name string `json:"name"`
+	jsonInputNode
+}
+
+// UnmarshalNBTJSON reads the typed JSON written by File.MarshalJSON.
+func UnmarshalNBTJSON(data []byte) (*File, error) {
+	var input jsonInputFile
+	if err := json.Unmarshal(data, &input); err != nil {
+		return nil, fmt.Errorf("unmarshal nbt json: %w", err)
+	}
+	root, err := fromJSONNode(input.jsonInputNode)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal nbt json: %w", err)
+	}
+	if root.Type() != NodeTypeCompound {
+		return nil, fmt.Errorf("unmarshal nbt json: %w, got type %v", ErrNotCompound, root.Type())
+	}
+	return &File{RootName: input.Name, Root: root}, nil
+}
+
+func jsonNodeType(typeName string) (NodeType, bool) {
+	for nodeType, name := range jsonTypeNames {
+		if name == typeName {
+			return nodeType, true
+		}
+	}
+	return 0, false
+}
+
+func fromJSONNode(input jsonInputNode) (Node, error) {
+	switch input.Type {
+	case "byte":
+		var val int8
+		err := json.Unmarshal(input.Value, &val)
+		return &ByteNode{Value: byte(val)}, err
+	case "short":
+		var val int16
+		err := json.Unmarshal(input.Value, &val)
+		return &ShortNode{Value: val}, err
+	case "int":
+		var val int32
+		err := json.Unmarshal(input.Value, &val)
+		return &IntNode{Value: val}, err
+	case "long":
+		var val int64
+		err := json.Unmarshal(input.Value, &val)
+		return &LongNode{Value: val}, err
+	case "float":
+		var val float32
+		err := json.Unmarshal(input.Value, &val)
+		return &FloatNode{Value: val}, err
+	case "double":
+		var val float64
+		err := json.Unmarshal(input.Value, &val)
+		return &DoubleNode{Value: val}, err
+	case "string":
+		var val string
+		err := json.Unmarshal(input.Value, &val)
+		return &StringNode{Value: val}, err
+
+	case "byte_array":
+		var values []int8
+		if err := json.Unmarshal(input.Value, &values); err != nil {
+			return nil, err
+		}
+		node := &ByteArrayNode{Values: make([]byte, len(values))}
+		for i, val := range values {
+			node.Values[i] = byte(val)
+		}
+		return node, nil
+	case "int_array":
+		var values []int32
+		if err := json.Unmarshal(input.Value, &values); err != nil {
+			return nil, err
+		}
+		node := &IntArrayNode{Values: make([]Node, len(values))}
+		for i, val := range values {
+			node.Values[i] = &IntNode{Value: val}
+		}
+		return node, nil
+	case "long_array":
+		var values []int64
+		err := json.Unmarshal(input.Value, &values)
+		return &LongArrayNode{Values: values}, err
+
+	case "list":
+		var values []jsonInputNode
+		if err := json.Unmarshal(input.Value, &values); err != nil {
+			return nil, err
+		}
+		node := &ListNode{Values: make([]Node, 0, len(values))}
+		if len(input.ElementType) > 0 {
+			elemType, ok := jsonNodeType(input.ElementType)
+			if !ok {
+				return nil, fmt.Errorf("unsupported list element type %q", input.ElementType)
+			}
+			node.ElementType = elemType
+		}
+		for i, value := range values {
+			childNode, err := fromJSONNode(value)
+			if err != nil {
+				return nil, fmt.Errorf("list index %d: %w", i, err)
+			}
+			if len(node.Values) > 0 && childNode.Type() != node.Values[0].Type() {
+				return nil, fmt.Errorf("list index %d: %w: type %v differs from list type %v", i, ErrInvalidList, childNode.Type(), node.Values[0].Type())
+			}
+			node.ElementType = childNode.Type()
+			node.Values = append(node.Values, childNode)
+		}
+		return node, nil
+	case "compound":
+		var values map[string]jsonInputNode
+		if err := json.Unmarshal(input.Value, &values); err != nil {
+			return nil, err
+		}
+		node := &CompoundNode{Values: make(map[string]Node, len(values))}
+		for key, value := range values {
+			childNode, err := fromJSONNode(value)
+			if err != nil {
+				return nil, fmt.Errorf("compound child %q: %w", key, err)
+			}
+			node.Values[key] = childNode
+		}
+		return node, nil
+	}
+	return nil, fmt.Errorf("unsupported node type %q", input.Type)
+}
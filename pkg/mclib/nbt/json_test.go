@@ -0,0 +1,77 @@
+package nbt
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	f, err := ParseSNBT(`{b:1b,s:1s,i:1,l:1L,f:1.5f,d:1.5d,str:"x",ba:[B;-1b,2b],ia:[I;1,-2],la:[L;3L],list:[1s,2s],nested:{a:{}}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := f.Root.(*CompoundNode)
+	root.Values["emptyInts"] = &ListNode{ElementType: NodeTypeInt}
+	root.Values["emptyEnd"] = &ListNode{}
+	f.RootName = "root"
+
+	data, err := json.Marshal(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := UnmarshalNBTJSON(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.RootName != "root" {
+		t.Errorf("root name = %q, want root", decoded.RootName)
+	}
+	if !Equal(decoded.Root, f.Root) {
+		t.Errorf("round trip changed the tree:\n%v\n%v", decoded, f)
+	}
+	for key, nodeType := range map[string]NodeType{"b": NodeTypeByte, "s": NodeTypeShort, "i": NodeTypeInt, "l": NodeTypeLong} {
+		if got := decoded.Root.(*CompoundNode).Values[key].Type(); got != nodeType {
+			t.Errorf("%s: type %v, want %v", key, got, nodeType)
+		}
+	}
+	decodedRoot := decoded.Root.(*CompoundNode)
+	if list, _ := decodedRoot.List("emptyInts"); list.ElementType != NodeTypeInt {
+		t.Errorf("empty list element type = %v, want %v", list.ElementType, NodeTypeInt)
+	}
+	if list, _ := decodedRoot.List("emptyEnd"); list.ElementType != NodeTypeEnd {
+		t.Errorf("empty list element type = %v, want %v", list.ElementType, NodeTypeEnd)
+	}
+	if list, _ := decodedRoot.List("list"); list.ElementType != NodeTypeShort {
+		t.Errorf("list element type = %v, want %v", list.ElementType, NodeTypeShort)
+	}
+}
+
+func TestJSONRejectsNaN(t *testing.T) {
+	f := NewFile("")
+	f.Root.(*CompoundNode).Set("nan", &DoubleNode{Value: math.NaN()})
+	if _, err := json.Marshal(f); err == nil {
+		t.Errorf("expected error for NaN")
+	}
+}
+
+func TestCompactJSON(t *testing.T) {
+	f, err := ParseSNBT(`{a:1b,b:[I;1,2]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := f.MarshalCompactJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"a":1,"b":[1,2]}` {
+		t.Errorf("compact JSON = %s", data)
+	}
+}
+
+func TestUnmarshalNBTJSONMixedList(t *testing.T) {
+	input := `{"name":"","type":"compound","value":{"l":{"type":"list","value":[{"type":"int","value":1},{"type":"byte","value":1}]}}}`
+	if _, err := UnmarshalNBTJSON([]byte(input)); err == nil {
+		t.Errorf("expected error for mixed list")
+	}
+}
@@ -0,0 +1,80 @@
+package nbt
+
+import "fmt"
+
+var worldSeedPaths = []string{
+	// since 1.16
+	"Data.WorldGenSettings.seed",
+	// before 1.16
+	"Data.RandomSeed",
+}
+
+// WorldSeed returns the world seed stored in a level.dat file.
+func WorldSeed(f *File) (int64, bool) {
+	for _, path := range worldSeedPaths {
+		for _, node := range f.Query(path).Nodes() {
+			if seed, ok := node.(*LongNode); ok {
+				return seed.Value, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// SetHardcore sets the hardcore flag of a level.dat file.
+func SetHardcore(f *File, on bool) error {
+	return setLevelFlag(f, "hardcore", on)
+}
+
+// SetAllowCommands sets whether cheats are enabled in a level.dat file.
+func SetAllowCommands(f *File, on bool) error {
+	return setLevelFlag(f, "allowCommands", on)
+}
+
+func setLevelFlag(f *File, key string, on bool) error {
+	sel := f.Query("Data")
+	if err := sel.Err(); err != nil {
+		return err
+	}
+	if sel.Len() == 0 {
+		return fmt.Errorf("set %q: missing Data compound", key)
+	}
+	return sel.Set(key, on).Err()
+}
+
+// LevelData holds commonly used fields of a level.dat file. Fields missing
+// from the file keep their zero value.
+type LevelData struct {
+	LevelName   string
+	SpawnX      int32
+	SpawnY      int32
+	SpawnZ      int32
+	GameType    int32
+	DayTime     int64
+	Difficulty  byte
+	DataVersion int32
+}
+
+// ParseLevelData extracts the fields of LevelData from the Data compound of a
+// level.dat file.
+func ParseLevelData(f *File) (*LevelData, error) {
+	rootNode, ok := f.rootCompound()
+	if !ok {
+		return nil, fmt.Errorf("parse level data: missing top-level compound")
+	}
+	data, ok := rootNode.Compound("Data")
+	if !ok {
+		return nil, fmt.Errorf("parse level data: missing Data compound")
+	}
+
+	var level LevelData
+	level.LevelName, _ = data.Str("LevelName")
+	level.SpawnX, _ = data.Int("SpawnX")
+	level.SpawnY, _ = data.Int("SpawnY")
+	level.SpawnZ, _ = data.Int("SpawnZ")
+	level.GameType, _ = data.Int("GameType")
+	level.DayTime, _ = data.Long("DayTime")
+	level.Difficulty, _ = data.Byte("Difficulty")
+	level.DataVersion, _ = data.Int("DataVersion")
+	return &level, nil
+}
@@ -0,0 +1,96 @@
+package nbt
+
+import "testing"
+
+func TestWorldSeed(t *testing.T) {
+	tests := map[string]struct {
+		snbt string
+		seed int64
+		ok   bool
+	}{
+		"before 1.16": {`{Data:{RandomSeed:-1234567890123L}}`, -1234567890123, true},
+		"since 1.16":  {`{Data:{WorldGenSettings:{seed:42L,bonus_chest:0b}}}`, 42, true},
+		"both":        {`{Data:{RandomSeed:1L,WorldGenSettings:{seed:2L}}}`, 2, true},
+		"missing":     {`{Data:{LevelName:"x"}}`, 0, false},
+		"wrong type":  {`{Data:{RandomSeed:5}}`, 0, false},
+	}
+	for name, test := range tests {
+		f, err := ParseSNBT(test.snbt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		seed, ok := WorldSeed(f)
+		if seed != test.seed || ok != test.ok {
+			t.Errorf("%s: WorldSeed() = %d, %v, want %d, %v", name, seed, ok, test.seed, test.ok)
+		}
+	}
+}
+
+func TestSetLevelFlags(t *testing.T) {
+	f, err := ParseSNBT(`{Data:{LevelName:"x",hardcore:0b}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := f.Root.(*CompoundNode).Values["Data"].(*CompoundNode)
+
+	for _, on := range []bool{true, false} {
+		if err := SetHardcore(f, on); err != nil {
+			t.Fatal(err)
+		}
+		if err := SetAllowCommands(f, on); err != nil {
+			t.Fatal(err)
+		}
+		for _, key := range []string{"hardcore", "allowCommands"} {
+			val, ok := data.Byte(key)
+			if !ok {
+				t.Errorf("%s is missing or not a byte: %v", key, data.Values[key])
+			} else if (val == 1) != on || val > 1 {
+				t.Errorf("%s = %d after setting %v", key, val, on)
+			}
+		}
+	}
+
+	if err := SetHardcore(NewFile(""), true); err == nil {
+		t.Errorf("expected error without Data compound")
+	}
+}
+
+func TestParseLevelData(t *testing.T) {
+	f, err := ReadFromFile("testdata/level.dat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	level, err := ParseLevelData(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := LevelData{
+		LevelName:   "Fixture World",
+		SpawnX:      -128,
+		SpawnY:      64,
+		SpawnZ:      48,
+		GameType:    0,
+		DayTime:     1875261,
+		Difficulty:  2,
+		DataVersion: 3465,
+	}
+	if *level != want {
+		t.Errorf("level data = %+v, want %+v", *level, want)
+	}
+
+	f, err = ParseSNBT(`{Data:{LevelName:"partial",SpawnX:"wrong type"}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if level, err := ParseLevelData(f); err != nil || *level != (LevelData{LevelName: "partial"}) {
+		t.Errorf("partial level data = %+v, %v", level, err)
+	}
+
+	f, err = ParseSNBT(`{LevelName:"no data"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseLevelData(f); err == nil {
+		t.Errorf("expected error for missing Data compound")
+	}
+}
@@ -0,0 +1,105 @@
+package nbt
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+)
+
+// Append adds node to the end of the list. All elements of a list must have
+// the same type, so a node of another type than the existing elements or the
+// ElementType of an empty list is rejected. Empty lists of NodeTypeEnd accept
+// any type.
+func (n *ListNode) Append(node Node) error {
+	if node == nil {
+		return fmt.Errorf("append nil node")
+	}
+	elemType := n.ElementType
+	if len(n.Values) > 0 {
+		elemType = n.Values[0].Type()
+	}
+	if elemType != NodeTypeEnd && node.Type() != elemType {
+		return fmt.Errorf("%w: node type %v differs from list type %v", ErrInvalidList, node.Type(), elemType)
+	}
+	n.ElementType = node.Type()
+	n.Values = append(n.Values, node)
+	return nil
+}
+
+// RemoveAt removes the element at index i and shifts all following elements.
+func (n *ListNode) RemoveAt(i int) error {
+	if i < 0 || i >= len(n.Values) {
+		return fmt.Errorf("index %d out of range for list of length %d", i, len(n.Values))
+	}
+	n.Values = slices.Delete(n.Values, i, i+1)
+	return nil
+}
+
+// Compounds returns the elements of a list of compounds, e.g. the items of an
+// inventory. Empty lists of NodeTypeEnd are accepted as the game writes them
+// for all empty lists.
+func (n *ListNode) Compounds() ([]*CompoundNode, error) {
+	if len(n.Values) == 0 && n.ElementType != NodeTypeEnd && n.ElementType != NodeTypeCompound {
+		return nil, fmt.Errorf("%w: expected compound elements, got list type %v", ErrInvalidList, n.ElementType)
+	}
+	compounds := make([]*CompoundNode, 0, len(n.Values))
+	for i, node := range n.Values {
+		compoundNode, ok := node.(*CompoundNode)
+		if !ok {
+			return nil, fmt.Errorf("list index %d: expected compound, got %T", i, node)
+		}
+		compounds = append(compounds, compoundNode)
+	}
+	return compounds, nil
+}
+
+// SortByKey stably sorts a list of compounds by the value at key, which must
+// be a number or string of the same type in every element.
+func (n *ListNode) SortByKey(key string) error {
+	var keyType NodeType
+	for i, node := range n.Values {
+		compoundNode, ok := node.(*CompoundNode)
+		if !ok {
+			return fmt.Errorf("list index %d: expected compound, got %T", i, node)
+		}
+		val := compoundNode.Values[key]
+		if val == nil {
+			return fmt.Errorf("list index %d: missing key %q", i, key)
+		}
+		if i == 0 {
+			keyType = val.Type()
+		} else if val.Type() != keyType {
+			return fmt.Errorf("list index %d: key %q has type %v instead of %v", i, key, val.Type(), keyType)
+		}
+	}
+
+	if len(n.Values) > 0 && !IsScalar(keyType) {
+		return fmt.Errorf("cannot sort by key %q of type %v", key, keyType)
+	}
+
+	slices.SortStableFunc(n.Values, func(a, b Node) int {
+		return compareScalars(a.(*CompoundNode).Values[key], b.(*CompoundNode).Values[key])
+	})
+	return nil
+}
+
+// compareScalars compares two scalar nodes of the same type.
+func compareScalars(a, b Node) int {
+	switch val := a.(type) {
+	case *ByteNode:
+		return cmp.Compare(int8(val.Value), int8(b.(*ByteNode).Value))
+	case *ShortNode:
+		return cmp.Compare(val.Value, b.(*ShortNode).Value)
+	case *IntNode:
+		return cmp.Compare(val.Value, b.(*IntNode).Value)
+	case *LongNode:
+		return cmp.Compare(val.Value, b.(*LongNode).Value)
+	case *FloatNode:
+		return cmp.Compare(val.Value, b.(*FloatNode).Value)
+	case *DoubleNode:
+		return cmp.Compare(val.Value, b.(*DoubleNode).Value)
+	case *StringNode:
+		return cmp.Compare(val.Value, b.(*StringNode).Value)
+	}
+	return 0
+}
@@ -0,0 +1,214 @@
+package nbt
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestReadEmptyListElementType(t *testing.T) {
+	// {l:[] of TAG_Int}
+	data := []byte{
+		0x0a, 0x00, 0x00,
+		0x09, 0x00, 0x01, 'l', 0x03, 0x00, 0x00, 0x00, 0x00,
+		0x00,
+	}
+	f, err := ReadFromBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	list, ok := f.Root.(*CompoundNode).List("l")
+	if !ok {
+		t.Fatal("missing list")
+	}
+	if list.ElementType != NodeTypeInt || len(list.Values) != 0 {
+		t.Errorf("list = %v with %d elements, want empty TAG_Int list", list.ElementType, len(list.Values))
+	}
+
+	var buf bytes.Buffer
+	if err := WriteToStream(&buf, f); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), data) {
+		t.Errorf("written %x, want %x", buf.Bytes(), data)
+	}
+}
+
+func TestReadInvalidListElementType(t *testing.T) {
+	for _, elemType := range []byte{13, 0x7f, 0xff} {
+		// {l:[] of the element type with 1000 elements}, without any payload
+		data := []byte{
+			0x0a, 0x00, 0x00,
+			0x09, 0x00, 0x01, 'l', elemType, 0x00, 0x00, 0x03, 0xe8,
+		}
+		if _, err := ReadFromBytes(data); !errors.Is(err, ErrUnsupportedNodeType) {
+			t.Errorf("element type %d: %v, want ErrUnsupportedNodeType", elemType, err)
+		}
+		// unknown tags can be retained, but not as list elements
+		_, err := ReadRawFromStreamWithOptions(bytes.NewReader(data), ReadOptions{SkipUnknownTags: true})
+		if !errors.Is(err, ErrInvalidList) {
+			t.Errorf("element type %d with SkipUnknownTags: %v, want ErrInvalidList", elemType, err)
+		}
+	}
+
+	// an end type list must not declare elements
+	data := []byte{
+		0x0a, 0x00, 0x00,
+		0x09, 0x00, 0x01, 'l', 0x00, 0x00, 0x00, 0x00, 0x01,
+		0x00,
+	}
+	if _, err := ReadFromBytes(data); !errors.Is(err, ErrInvalidList) {
+		t.Errorf("end type list with elements: %v, want ErrInvalidList", err)
+	}
+}
+
+func TestParseSNBTListElementType(t *testing.T) {
+	f, err := ParseSNBT(`{l:[1s,2s],e:[]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := f.Root.(*CompoundNode)
+	if list, _ := root.List("l"); list.ElementType != NodeTypeShort {
+		t.Errorf("element type = %v, want %v", list.ElementType, NodeTypeShort)
+	}
+	if list, _ := root.List("e"); list.ElementType != NodeTypeEnd {
+		t.Errorf("element type of empty list = %v, want %v", list.ElementType, NodeTypeEnd)
+	}
+}
+
+func TestListAppend(t *testing.T) {
+	list := &ListNode{ElementType: NodeTypeString}
+	if err := list.Append(&IntNode{}); !errors.Is(err, ErrInvalidList) {
+		t.Errorf("Append of int to string list: %v, want ErrInvalidList", err)
+	}
+	if err := list.Append(&StringNode{Value: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := list.Append(nil); err == nil {
+		t.Errorf("expected error appending nil")
+	}
+
+	untyped := &ListNode{}
+	if err := untyped.Append(&IntNode{}); err != nil {
+		t.Fatal(err)
+	}
+	if untyped.ElementType != NodeTypeInt {
+		t.Errorf("element type = %v, want %v", untyped.ElementType, NodeTypeInt)
+	}
+	if err := untyped.Append(&ByteNode{}); err == nil {
+		t.Errorf("expected error appending byte to int list")
+	}
+}
+
+func TestListRemoveAt(t *testing.T) {
+	list := &ListNode{Values: []Node{&IntNode{Value: 1}, &IntNode{Value: 2}, &IntNode{Value: 3}}}
+	if err := list.RemoveAt(1); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Values) != 2 || list.Values[1].(*IntNode).Value != 3 {
+		t.Errorf("list after RemoveAt = %v", list)
+	}
+	if err := list.RemoveAt(2); err == nil {
+		t.Errorf("expected error for index out of range")
+	}
+}
+
+func TestSortByKey(t *testing.T) {
+	f, err := ParseSNBT(`{Inventory:[{Slot:3b,id:"c"},{Slot:-106b,id:"offhand"},{Slot:0b,id:"a"},{Slot:3b,id:"d"},{Slot:1b,id:"b"}]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inventory, _ := f.Root.(*CompoundNode).List("Inventory")
+	if err := inventory.SortByKey("Slot"); err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, node := range inventory.Values {
+		id, _ := node.(*CompoundNode).Str("id")
+		ids = append(ids, id)
+	}
+	// bytes are signed and equal slots keep their order
+	if want := []string{"offhand", "a", "b", "c", "d"}; !slices.Equal(ids, want) {
+		t.Errorf("sorted ids = %v, want %v", ids, want)
+	}
+
+	for _, snbt := range []string{
+		`{l:[1,2]}`,
+		`{l:[{Slot:1b},{id:"x"}]}`,
+		`{l:[{Slot:1b},{Slot:2}]}`,
+		`{l:[{Slot:{}},{Slot:{}}]}`,
+	} {
+		f, err := ParseSNBT(snbt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		list, _ := f.Root.(*CompoundNode).List("l")
+		if err := list.SortByKey("Slot"); err == nil {
+			t.Errorf("%s: expected error", snbt)
+		}
+	}
+}
+
+func TestReadShortList(t *testing.T) {
+	// {l:[1s,2s,3s]}
+	data := []byte{
+		0x0a, 0x00, 0x00,
+		0x09, 0x00, 0x01, 'l', 0x02, 0x00, 0x00, 0x00, 0x03,
+		0x00, 0x01, 0x00, 0x02, 0x00, 0x03,
+		0x00,
+	}
+	f, err := ReadFromBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	list, ok := f.Root.(*CompoundNode).List("l")
+	if !ok {
+		t.Fatal("missing list")
+	}
+	if len(list.Values) != 3 {
+		t.Fatalf("list has %d elements, want 3", len(list.Values))
+	}
+	for i, node := range list.Values {
+		short, ok := node.(*ShortNode)
+		if !ok || short.Value != int16(i+1) {
+			t.Errorf("element %d = %v, want %ds", i, node, i+1)
+		}
+	}
+}
+
+func TestListCompounds(t *testing.T) {
+	f := testInventoryFile(t)
+	inventory, err := f.GetPath("Data.Player.Inventory")
+	if err != nil {
+		t.Fatal(err)
+	}
+	items, err := inventory.(*ListNode).Compounds()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, item := range items {
+		id, ok := item.Str("id")
+		if !ok {
+			t.Errorf("item without id: %s", ToSNBT(item))
+		}
+		ids = append(ids, id)
+	}
+	if want := []string{"minecraft:stone", "minecraft:dirt"}; !slices.Equal(ids, want) {
+		t.Errorf("ids = %v, want %v", ids, want)
+	}
+
+	// empty lists as written by the game have no element type
+	if items, err := (&ListNode{ElementType: NodeTypeEnd}).Compounds(); err != nil || len(items) != 0 {
+		t.Errorf("Compounds of empty list = %v, %v", items, err)
+	}
+	for _, list := range []*ListNode{
+		{ElementType: NodeTypeInt},
+		{ElementType: NodeTypeInt, Values: []Node{&IntNode{Value: 1}}},
+	} {
+		if _, err := list.Compounds(); err == nil {
+			t.Errorf("expected error for list of %v", list.ElementType)
+		}
+	}
+}
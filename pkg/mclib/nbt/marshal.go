@@ -0,0 +1,457 @@
+package nbt
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// Marshal converts v into a node, which is a compound for structs and maps
+// with string keys. Struct fields are named like for Unmarshal and tagged
+// with ",omitempty" to skip zero values. Go types determine the node types:
+// bool, int8 and uint8 become bytes, int16 shorts, int32 and int ints, int64
+// longs, uint16 ints and wider unsigned integers longs. Like File.Query's Set,
+// an int outside the int32 range is an error. []byte and []int8 become
+// byte arrays, []int32 int arrays and []int64 long arrays, while other slices
+// become lists. Nodes are used as they are. Nil pointers, interfaces, slices
+// and maps in fields are omitted, as NBT has no null value.
+func Marshal(v interface{}) (Node, error) {
+	node, ok, err := marshalValue(reflect.ValueOf(v))
+	if err != nil {
+		return nil, fmt.Errorf("marshal: %w", err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("marshal: nil value")
+	}
+	return node, nil
+}
+
+var nodeInterface = reflect.TypeOf((*Node)(nil)).Elem()
+
+// marshalValue returns false for values without representation like nil
+// pointers.
+func marshalValue(rv reflect.Value) (Node, bool, error) {
+	if !rv.IsValid() {
+		return nil, false, nil
+	}
+	if rv.Type().Implements(nodeInterface) {
+		switch rv.Kind() {
+		case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+			if rv.IsNil() {
+				return nil, false, nil
+			}
+		}
+		return rv.Interface().(Node), true, nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return nil, false, nil
+		}
+		return marshalValue(rv.Elem())
+
+	case reflect.Bool:
+		if rv.Bool() {
+			return &ByteNode{Value: 1}, true, nil
+		}
+		return &ByteNode{Value: 0}, true, nil
+	case reflect.Int8:
+		return &ByteNode{Value: byte(rv.Int())}, true, nil
+	case reflect.Uint8:
+		return &ByteNode{Value: byte(rv.Uint())}, true, nil
+	case reflect.Int16:
+		return &ShortNode{Value: int16(rv.Int())}, true, nil
+	case reflect.Int32:
+		return &IntNode{Value: int32(rv.Int())}, true, nil
+	case reflect.Int:
+		if rv.Int() < math.MinInt32 || rv.Int() > math.MaxInt32 {
+			return nil, false, fmt.Errorf("int value %d exceeds int32 range", rv.Int())
+		}
+		return &IntNode{Value: int32(rv.Int())}, true, nil
+	case reflect.Uint16:
+		return &IntNode{Value: int32(rv.Uint())}, true, nil
+	case reflect.Int64:
+		return &LongNode{Value: rv.Int()}, true, nil
+	case reflect.Uint32, reflect.Uint, reflect.Uint64:
+		if rv.Uint() > math.MaxInt64 {
+			return nil, false, fmt.Errorf("value %d overflows long", rv.Uint())
+		}
+		return &LongNode{Value: int64(rv.Uint())}, true, nil
+	case reflect.Float32:
+		return &FloatNode{Value: float32(rv.Float())}, true, nil
+	case reflect.Float64:
+		return &DoubleNode{Value: rv.Float()}, true, nil
+	case reflect.String:
+		return &StringNode{Value: rv.String()}, true, nil
+
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return nil, false, nil
+		}
+		node, err := marshalSlice(rv)
+		return node, err == nil, err
+	case reflect.Map:
+		if rv.IsNil() {
+			return nil, false, nil
+		}
+		node, err := marshalMap(rv)
+		return node, err == nil, err
+	case reflect.Struct:
+		node, err := marshalStruct(rv)
+		return node, err == nil, err
+	}
+	return nil, false, fmt.Errorf("unsupported type %v", rv.Type())
+}
+
+func marshalSlice(rv reflect.Value) (Node, error) {
+	switch rv.Type().Elem().Kind() {
+	case reflect.Uint8:
+		values := make([]byte, rv.Len())
+		for i := range values {
+			values[i] = byte(rv.Index(i).Uint())
+		}
+		return &ByteArrayNode{Values: values}, nil
+	case reflect.Int8:
+		values := make([]byte, rv.Len())
+		for i := range values {
+			values[i] = byte(rv.Index(i).Int())
+		}
+		return &ByteArrayNode{Values: values}, nil
+	case reflect.Int32:
+		values := make([]Node, rv.Len())
+		for i := range values {
+			values[i] = &IntNode{Value: int32(rv.Index(i).Int())}
+		}
+		return &IntArrayNode{Values: values}, nil
+	case reflect.Int64:
+		values := make([]int64, rv.Len())
+		for i := range values {
+			values[i] = rv.Index(i).Int()
+		}
+		return &LongArrayNode{Values: values}, nil
+	}
+
+	list := &ListNode{Values: make([]Node, 0, rv.Len())}
+	for i := range rv.Len() {
+		childNode, ok, err := marshalValue(rv.Index(i))
+		if err != nil {
+			return nil, fmt.Errorf("index %d: %w", i, err)
+		}
+		if !ok {
+			return nil, fmt.Errorf("index %d: nil value", i)
+		}
+		if len(list.Values) > 0 && childNode.Type() != list.Values[0].Type() {
+			return nil, fmt.Errorf("index %d: node type %v differs from list type %v", i, childNode.Type(), list.Values[0].Type())
+		}
+		list.Values = append(list.Values, childNode)
+	}
+	return list, nil
+}
+
+func marshalMap(rv reflect.Value) (Node, error) {
+	if rv.Type().Key().Kind() != reflect.String {
+		return nil, fmt.Errorf("unsupported map key type %v", rv.Type().Key())
+	}
+	compound := &CompoundNode{Values: make(map[string]Node, rv.Len())}
+	iter := rv.MapRange()
+	for iter.Next() {
+		key := iter.Key().String()
+		childNode, ok, err := marshalValue(iter.Value())
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", key, err)
+		}
+		if ok {
+			compound.Values[key] = childNode
+		}
+	}
+	return compound, nil
+}
+
+func marshalStruct(rv reflect.Value) (Node, error) {
+	compound := &CompoundNode{Values: make(map[string]Node, rv.NumField())}
+	for i := range rv.NumField() {
+		field := rv.Type().Field(i)
+		key, ok := fieldKey(field)
+		if !ok {
+			continue
+		}
+		if hasTagOption(field, "omitempty") && isEmptyValue(rv.Field(i)) {
+			continue
+		}
+		childNode, ok, err := marshalValue(rv.Field(i))
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		if ok {
+			compound.Set(key, childNode)
+		}
+	}
+	return compound, nil
+}
+
+func hasTagOption(field reflect.StructField, option string) bool {
+	_, options, _ := strings.Cut(field.Tag.Get("nbt"), ",")
+	for _, opt := range strings.Split(options, ",") {
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
+
+// isEmptyValue reports whether a field is skipped with omitempty, which
+// includes empty slices and maps.
+func isEmptyValue(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Map:
+		return rv.Len() == 0
+	}
+	return rv.IsZero()
+}
+
+// Unmarshal stores the data of node in the value pointed to by v. Structs are
+// filled from compounds, where the key of a field is given by its tag like
+// `nbt:"Name"` and defaults to the field name. Fields tagged with "-" and keys
+// missing in the compound are skipped. Integer fields accept any integer node
+// whose value fits, where bytes are signed for signed and unsigned for
+// unsigned fields. Float fields accept float and double nodes, bool fields
+// bytes, slices lists and arrays, and maps with string keys compounds. Fields
+// of type interface{} receive the value of GoValue, while fields of a node type
+// like *CompoundNode or Node receive the node itself.
+func Unmarshal(node Node, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("unmarshal: expected non-nil pointer, got %T", v)
+	}
+	if err := unmarshalValue(node, rv.Elem()); err != nil {
+		return fmt.Errorf("unmarshal: %w", err)
+	}
+	return nil
+}
+
+func unmarshalValue(node Node, rv reflect.Value) error {
+	if node == nil {
+		return fmt.Errorf("nil node")
+	}
+	if rv.Kind() == reflect.Interface && rv.NumMethod() == 0 {
+		rv.Set(reflect.ValueOf(GoValue(node)))
+		return nil
+	}
+	if nodeValue := reflect.ValueOf(node); nodeValue.Type().AssignableTo(rv.Type()) {
+		rv.Set(nodeValue)
+		return nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Pointer:
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return unmarshalValue(node, rv.Elem())
+
+	case reflect.Bool:
+		val, ok := node.(*ByteNode)
+		if !ok {
+			return typeMismatch(node, rv)
+		}
+		rv.SetBool(val.Value != 0)
+		return nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		val, ok := integerValue(node)
+		if !ok {
+			return typeMismatch(node, rv)
+		}
+		if rv.OverflowInt(val) {
+			return fmt.Errorf("value %d overflows %v", val, rv.Type())
+		}
+		rv.SetInt(val)
+		return nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		val, ok := integerValue(node)
+		if n, isByte := node.(*ByteNode); isByte {
+			// bytes are unsigned in Go, so keep values above 127 for unsigned targets
+			val = int64(n.Value)
+		}
+		if !ok {
+			return typeMismatch(node, rv)
+		}
+		if val < 0 || rv.OverflowUint(uint64(val)) {
+			return fmt.Errorf("value %d overflows %v", val, rv.Type())
+		}
+		rv.SetUint(uint64(val))
+		return nil
+
+	case reflect.Float32, reflect.Float64:
+		switch n := node.(type) {
+		case *FloatNode:
+			rv.SetFloat(float64(n.Value))
+		case *DoubleNode:
+			rv.SetFloat(n.Value)
+		default:
+			return typeMismatch(node, rv)
+		}
+		return nil
+
+	case reflect.String:
+		val, ok := Str(node)
+		if !ok {
+			return typeMismatch(node, rv)
+		}
+		rv.SetString(val)
+		return nil
+
+	case reflect.Slice:
+		return unmarshalSlice(node, rv)
+	case reflect.Array:
+		return unmarshalArray(node, rv)
+	case reflect.Map:
+		return unmarshalMap(node, rv)
+	case reflect.Struct:
+		return unmarshalStruct(node, rv)
+	}
+	return fmt.Errorf("unsupported type %v", rv.Type())
+}
+
+func integerValue(node Node) (int64, bool) {
+	switch n := node.(type) {
+	case *ByteNode:
+		return int64(int8(n.Value)), true
+	case *ShortNode:
+		return int64(n.Value), true
+	case *IntNode:
+		return int64(n.Value), true
+	case *LongNode:
+		return n.Value, true
+	}
+	return 0, false
+}
+
+func typeMismatch(node Node, rv reflect.Value) error {
+	return fmt.Errorf("cannot store %T in %v", node, rv.Type())
+}
+
+func unmarshalSlice(node Node, rv reflect.Value) error {
+	if n, ok := node.(*ByteArrayNode); ok && rv.Type().Elem().Kind() == reflect.Uint8 {
+		rv.SetBytes(append([]byte(nil), n.Values...))
+		return nil
+	}
+	elements, ok := sequenceElements(node)
+	if !ok {
+		return typeMismatch(node, rv)
+	}
+
+	slice := reflect.MakeSlice(rv.Type(), len(elements), len(elements))
+	if err := unmarshalElements(elements, slice); err != nil {
+		return err
+	}
+	rv.Set(slice)
+	return nil
+}
+
+func unmarshalArray(node Node, rv reflect.Value) error {
+	elements, ok := sequenceElements(node)
+	if !ok {
+		return typeMismatch(node, rv)
+	}
+	if len(elements) != rv.Len() {
+		return fmt.Errorf("cannot store %d elements in %v", len(elements), rv.Type())
+	}
+	return unmarshalElements(elements, rv)
+}
+
+// sequenceElements returns the elements of a list or array node.
+func sequenceElements(node Node) ([]Node, bool) {
+	switch n := node.(type) {
+	case *ListNode:
+		return n.Values, true
+	case *IntArrayNode:
+		return n.Values, true
+	case *ByteArrayNode:
+		elements := make([]Node, len(n.Values))
+		for i, val := range n.Values {
+			elements[i] = &ByteNode{Value: val}
+		}
+		return elements, true
+	case *LongArrayNode:
+		elements := make([]Node, len(n.Values))
+		for i, val := range n.Values {
+			elements[i] = &LongNode{Value: val}
+		}
+		return elements, true
+	}
+	return nil, false
+}
+
+func unmarshalElements(elements []Node, rv reflect.Value) error {
+	for i, childNode := range elements {
+		if err := unmarshalValue(childNode, rv.Index(i)); err != nil {
+			return fmt.Errorf("index %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func unmarshalMap(node Node, rv reflect.Value) error {
+	compound, ok := node.(*CompoundNode)
+	if !ok {
+		return typeMismatch(node, rv)
+	}
+	if rv.Type().Key().Kind() != reflect.String {
+		return fmt.Errorf("unsupported map key type %v", rv.Type().Key())
+	}
+
+	if rv.IsNil() {
+		rv.Set(reflect.MakeMapWithSize(rv.Type(), len(compound.Values)))
+	}
+	for _, key := range compound.SortedKeys() {
+		val := reflect.New(rv.Type().Elem()).Elem()
+		if err := unmarshalValue(compound.Values[key], val); err != nil {
+			return fmt.Errorf("key %q: %w", key, err)
+		}
+		rv.SetMapIndex(reflect.ValueOf(key).Convert(rv.Type().Key()), val)
+	}
+	return nil
+}
+
+func unmarshalStruct(node Node, rv reflect.Value) error {
+	compound, ok := node.(*CompoundNode)
+	if !ok {
+		return typeMismatch(node, rv)
+	}
+
+	for i := range rv.NumField() {
+		field := rv.Type().Field(i)
+		key, ok := fieldKey(field)
+		if !ok {
+			continue
+		}
+		childNode, exists := compound.Values[key]
+		if !exists {
+			continue
+		}
+		if err := unmarshalValue(childNode, rv.Field(i)); err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+	}
+	return nil
+}
+
+// fieldKey returns the compound key of a struct field, or false if the field
+// is unexported or tagged with "-".
+func fieldKey(field reflect.StructField) (string, bool) {
+	if !field.IsExported() {
+		return "", false
+	}
+	tag, _, _ := strings.Cut(field.Tag.Get("nbt"), ",")
+	if tag == "-" {
+		return "", false
+	}
+	if len(tag) > 0 {
+		return tag, true
+	}
+	return field.Name, true
+}
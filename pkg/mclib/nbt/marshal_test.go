@@ -0,0 +1,253 @@
+package nbt
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testAbilities struct {
+	Flying   bool    `nbt:"flying"`
+	FlySpeed float32 `nbt:"flySpeed"`
+}
+
+type testPlayer struct {
+	Name      string        `nbt:"Name"`
+	Pos       []float64     `nbt:"Pos"`
+	Health    float32       `nbt:"Health"`
+	XpLevel   int32         `nbt:"XpLevel"`
+	Score     int           `nbt:"Score"`
+	Seed      int64         `nbt:"Seed"`
+	FoodLevel int16         `nbt:"foodLevel"`
+	Abilities testAbilities `nbt:"abilities"`
+	Tags      []string      `nbt:"Tags,omitempty"`
+	Ignored   string        `nbt:"-"`
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	player := testPlayer{
+		Name:      "Steve",
+		Pos:       []float64{1.5, 64, -20.25},
+		Health:    20,
+		XpLevel:   30,
+		Score:     -7,
+		Seed:      -4172144997902289642,
+		FoodLevel: 18,
+		Abilities: testAbilities{Flying: true, FlySpeed: 0.05},
+		Ignored:   "not marshalled",
+	}
+
+	node, err := Marshal(player)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compound := node.(*CompoundNode)
+	if _, ok := compound.Values["Tags"]; ok {
+		t.Errorf("empty Tags should be omitted")
+	}
+	if _, ok := compound.Values["Ignored"]; ok {
+		t.Errorf("field tagged with - should be omitted")
+	}
+
+	var decoded testPlayer
+	if err := Unmarshal(node, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	player.Ignored = ""
+	if !reflect.DeepEqual(decoded, player) {
+		t.Errorf("round trip changed value:\ngot  %+v\nwant %+v", decoded, player)
+	}
+}
+
+// testColor is a Node implemented with a value receiver.
+type testColor struct {
+	RGB int32
+}
+
+func (testColor) Type() NodeType { return NodeTypeInt }
+
+func TestMarshalNodeFields(t *testing.T) {
+	type entity struct {
+		Color  testColor
+		Custom Node
+		Pos    [3]float64
+	}
+	value := entity{
+		Color:  testColor{RGB: 0xff0000},
+		Custom: &StringNode{Value: "raw"},
+		Pos:    [3]float64{1.5, 64, -20.25},
+	}
+
+	node, err := Marshal(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compound := node.(*CompoundNode)
+	if color, ok := compound.Values["Color"].(testColor); !ok || color.RGB != 0xff0000 {
+		t.Errorf("Color = %#v, want the node itself", compound.Values["Color"])
+	}
+
+	var decoded entity
+	if err := Unmarshal(node, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, value) {
+		t.Errorf("round trip changed value:\ngot  %+v\nwant %+v", decoded, value)
+	}
+
+	// nil nodes have no representation
+	node, err = Marshal(entity{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := node.(*CompoundNode).Values["Custom"]; ok {
+		t.Errorf("nil Custom should be omitted")
+	}
+}
+
+func TestMarshalNodeTypes(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		nodeType NodeType
+	}{
+		{true, NodeTypeByte},
+		{int8(-1), NodeTypeByte},
+		{uint8(200), NodeTypeByte},
+		{int16(1), NodeTypeShort},
+		{uint16(1), NodeTypeInt},
+		{int32(1), NodeTypeInt},
+		{int(1), NodeTypeInt},
+		{int64(1), NodeTypeLong},
+		{uint32(1), NodeTypeLong},
+		{float32(1), NodeTypeFloat},
+		{float64(1), NodeTypeDouble},
+		{"a", NodeTypeString},
+		{[]byte{1}, NodeTypeByteArray},
+		{[]int32{1}, NodeTypeIntArray},
+		{[]int64{1}, NodeTypeLongArray},
+		{[]string{"a"}, NodeTypeList},
+		{map[string]int32{"a": 1}, NodeTypeCompound},
+	}
+	for _, test := range tests {
+		node, err := Marshal(test.value)
+		if err != nil {
+			t.Errorf("Marshal(%T): %v", test.value, err)
+			continue
+		}
+		if node.Type() != test.nodeType {
+			t.Errorf("Marshal(%T) = %v, want %v", test.value, node.Type(), test.nodeType)
+		}
+	}
+}
+
+func TestMarshalIntMatchesQuerySet(t *testing.T) {
+	type value struct{ A int }
+	node, err := Marshal(value{A: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := NewFile("")
+	if err := f.Query("").Set("A", 5).Err(); err != nil {
+		t.Fatal(err)
+	}
+	if !Equal(node, f.Root) {
+		t.Errorf("Marshal and Set disagree: %v vs %v", node, f.Root)
+	}
+
+	if _, err := Marshal(value{A: 1 << 40}); err == nil {
+		t.Errorf("expected error for int outside the int32 range")
+	}
+}
+
+func TestMarshalLevelDataDifficulty(t *testing.T) {
+	node, err := Marshal(LevelData{Difficulty: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	difficulty, ok := node.(*CompoundNode).Values["Difficulty"].(*ByteNode)
+	if !ok || difficulty.Value != 2 {
+		t.Errorf("Difficulty = %v, want TAG_Byte 2", node.(*CompoundNode).Values["Difficulty"])
+	}
+}
+
+func TestUnmarshalPlayer(t *testing.T) {
+	f, err := ParseSNBT(`{Name:"Alex",Pos:[12.5d,70.0d,-3.25d],Health:19.5f,XpLevel:3,Score:4,Seed:9L,foodLevel:20s,abilities:{flying:0b,flySpeed:0.05f},Extra:1b}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var player testPlayer
+	if err := Unmarshal(f.Root, &player); err != nil {
+		t.Fatal(err)
+	}
+	want := testPlayer{
+		Name:      "Alex",
+		Pos:       []float64{12.5, 70, -3.25},
+		Health:    19.5,
+		XpLevel:   3,
+		Score:     4,
+		Seed:      9,
+		FoodLevel: 20,
+		Abilities: testAbilities{Flying: false, FlySpeed: 0.05},
+	}
+	if !reflect.DeepEqual(player, want) {
+		t.Errorf("got  %+v\nwant %+v", player, want)
+	}
+}
+
+func TestUnmarshalArray(t *testing.T) {
+	f, err := ParseSNBT(`{Pos:[12.5d,70.0d,-3.25d],UUID:[I;1,2,3,4]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entity struct {
+		Pos  [3]float64
+		UUID [4]int32
+	}
+	if err := Unmarshal(f.Root, &entity); err != nil {
+		t.Fatal(err)
+	}
+	if entity.Pos != [3]float64{12.5, 70, -3.25} || entity.UUID != [4]int32{1, 2, 3, 4} {
+		t.Errorf("got %+v", entity)
+	}
+
+	var short struct {
+		Pos [2]float64
+	}
+	err = Unmarshal(f.Root, &short)
+	if err == nil || !strings.Contains(err.Error(), "cannot store 3 elements in [2]float64") {
+		t.Errorf("length mismatch: %v", err)
+	}
+}
+
+func TestUnmarshalByteSignedness(t *testing.T) {
+	var unsigned byte
+	if err := Unmarshal(&ByteNode{Value: 200}, &unsigned); err != nil {
+		t.Fatal(err)
+	}
+	if unsigned != 200 {
+		t.Errorf("byte = %d, want 200", unsigned)
+	}
+
+	var signed int8
+	if err := Unmarshal(&ByteNode{Value: 200}, &signed); err != nil {
+		t.Fatal(err)
+	}
+	if signed != -56 {
+		t.Errorf("int8 = %d, want -56", signed)
+	}
+
+	var wide uint16
+	if err := Unmarshal(&ShortNode{Value: -1}, &wide); err == nil {
+		t.Errorf("expected overflow error for negative short in uint16")
+	}
+}
+
+func TestUnmarshalTypeMismatch(t *testing.T) {
+	var val int32
+	if err := Unmarshal(&StringNode{Value: "x"}, &val); err == nil {
+		t.Errorf("expected error storing a string in an int32")
+	}
+	if err := Unmarshal(&IntNode{}, val); err == nil {
+		t.Errorf("expected error for non-pointer target")
+	}
+}
@@ -0,0 +1,69 @@
+package nbt
+
+import "unsafe"
+
+const (
+	interfaceSize = int64(unsafe.Sizeof(Node(nil)))
+	stringSize    = int64(unsafe.Sizeof(""))
+	// mapEntryOverhead approximates the share of map buckets that is not
+	// occupied at the average load factor.
+	mapEntryOverhead = 1.25
+)
+
+// MemorySize estimates the number of bytes the tree occupies in memory,
+// including struct sizes, the backing storage of slices and maps, and string
+// contents. It differs from the encoded size and is meant for decisions like
+// batching. Nodes of custom types are not accounted for.
+func (f *File) MemorySize() int64 {
+	size := int64(unsafe.Sizeof(*f)) + int64(len(f.RootName))
+	for _, warning := range f.Warnings {
+		size += int64(unsafe.Sizeof(warning)) + int64(len(warning.Path)+len(warning.Message))
+	}
+	return size + nodeMemorySize(f.Root)
+}
+
+func nodeMemorySize(node Node) int64 {
+	switch n := node.(type) {
+	case *ByteNode:
+		return int64(unsafe.Sizeof(*n))
+	case *ShortNode:
+		return int64(unsafe.Sizeof(*n))
+	case *IntNode:
+		return int64(unsafe.Sizeof(*n))
+	case *LongNode:
+		return int64(unsafe.Sizeof(*n))
+	case *FloatNode:
+		return int64(unsafe.Sizeof(*n))
+	case *DoubleNode:
+		return int64(unsafe.Sizeof(*n))
+	case *ByteArrayNode:
+		return int64(unsafe.Sizeof(*n)) + int64(cap(n.Values))
+	case *StringNode:
+		return int64(unsafe.Sizeof(*n)) + int64(len(n.Value))
+	case *ListNode:
+		size := int64(unsafe.Sizeof(*n)) + int64(cap(n.Values))*interfaceSize
+		for _, childNode := range n.Values {
+			size += nodeMemorySize(childNode)
+		}
+		return size
+	case *CompoundNode:
+		size := int64(unsafe.Sizeof(*n)) + int64(float64(len(n.Values))*float64(stringSize+interfaceSize+1)*mapEntryOverhead)
+		for key, childNode := range n.Values {
+			size += int64(len(key)) + nodeMemorySize(childNode)
+		}
+		return size
+	case *IntArrayNode:
+		size := int64(unsafe.Sizeof(*n)) + int64(cap(n.Values))*interfaceSize
+		for _, childNode := range n.Values {
+			size += nodeMemorySize(childNode)
+		}
+		return size
+	case *LongArrayNode:
+		return int64(unsafe.Sizeof(*n)) + int64(cap(n.Values))*8
+	case *UnknownNode:
+		return int64(unsafe.Sizeof(*n)) + int64(cap(n.Raw))
+
+	default:
+		return 0
+	}
+}
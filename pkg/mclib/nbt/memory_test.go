@@ -0,0 +1,24 @@
+package nbt
+
+import "testing"
+
+func TestMemorySize(t *testing.T) {
+	f := NewFile("")
+	f.Root.(*CompoundNode).Set("data", &ByteArrayNode{Values: make([]byte, 1<<20)})
+	// the array dominates, the nodes around it take a few hundred bytes at most
+	if size := f.MemorySize(); size < 1<<20 || size > 1<<20+1024 {
+		t.Errorf("size with 1 MiB byte array = %d", size)
+	}
+
+	before := f.MemorySize()
+	f.Root.(*CompoundNode).Set("name", &StringNode{Value: "minecraft:stone"})
+	after := f.MemorySize()
+	if after <= before+int64(len("name")+len("minecraft:stone")) {
+		t.Errorf("adding a string grows the size from %d to %d only", before, after)
+	}
+
+	small := testFile(t).MemorySize()
+	if small < 200 || small > 8192 {
+		t.Errorf("size of small test file = %d", small)
+	}
+}
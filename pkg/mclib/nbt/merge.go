@@ -0,0 +1,53 @@
+package nbt
+
+type ListStrategy int
+
+const (
+	// ListReplace replaces a list in dst by the list of src.
+	ListReplace ListStrategy = iota
+	// ListAppend appends the elements of a list in src to the list in dst if
+	// both hold elements of the same type, and replaces it otherwise.
+	ListAppend
+)
+
+type MergeOptions struct {
+	ListStrategy ListStrategy
+}
+
+// Merge overlays src onto dst and returns dst. Keys only present in src are
+// added, compounds present in both are merged recursively and all other
+// values of dst are replaced by those of src. Nodes taken from src are
+// cloned, so the result does not share any nodes with src.
+func Merge(dst, src *CompoundNode, opts MergeOptions) *CompoundNode {
+	if dst.Values == nil {
+		dst.Values = make(map[string]Node, len(src.Values))
+	}
+	for _, key := range src.Keys() {
+		srcNode := src.Values[key]
+		dstNode := dst.Values[key]
+		switch srcChild := srcNode.(type) {
+		case *CompoundNode:
+			if dstChild, ok := dstNode.(*CompoundNode); ok {
+				Merge(dstChild, srcChild, opts)
+				continue
+			}
+		case *ListNode:
+			if dstChild, ok := dstNode.(*ListNode); ok && opts.ListStrategy == ListAppend && canAppendList(dstChild, srcChild) {
+				dstChild.Values = append(dstChild.Values, cloneNodes(srcChild.Values)...)
+				if len(srcChild.Values) > 0 {
+					dstChild.ElementType = srcChild.Values[0].Type()
+				}
+				continue
+			}
+		}
+		dst.Set(key, Clone(srcNode))
+	}
+	return dst
+}
+
+func canAppendList(dst, src *ListNode) bool {
+	if len(dst.Values) == 0 || len(src.Values) == 0 {
+		return true
+	}
+	return dst.Values[0].Type() == src.Values[0].Type()
+}
@@ -0,0 +1,58 @@
+package nbt
+
+import "testing"
+
+func parseCompound(t *testing.T, snbt string) *CompoundNode {
+	t.Helper()
+	f, err := ParseSNBT(snbt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f.Root.(*CompoundNode)
+}
+
+func TestMergeNested(t *testing.T) {
+	dst := parseCompound(t, `{Data:{LevelName:"old",Version:{Id:3465,Name:"1.20.1"},GameRules:{keepInventory:"false"}},Kept:1b}`)
+	src := parseCompound(t, `{Data:{LevelName:"new",Version:{Id:3700},GameRules:{doFireTick:"false"},hardcore:1b}}`)
+
+	merged := Merge(dst, src, MergeOptions{})
+	if merged != dst {
+		t.Errorf("Merge does not return dst")
+	}
+	want := parseCompound(t, `{Data:{LevelName:"new",Version:{Id:3700,Name:"1.20.1"},GameRules:{keepInventory:"false",doFireTick:"false"},hardcore:1b},Kept:1b}`)
+	if !Equal(dst, want) {
+		t.Errorf("merged %s, want %s", ToSNBT(dst), ToSNBT(want))
+	}
+
+	// nodes taken from src are not shared
+	data, _ := src.Compound("Data")
+	data.Values["hardcore"].(*ByteNode).Value = 0
+	if val, _ := merged.Values["Data"].(*CompoundNode).Byte("hardcore"); val != 1 {
+		t.Errorf("merged tree shares nodes with src")
+	}
+}
+
+func TestMergeListStrategies(t *testing.T) {
+	const (
+		dstSNBT = `{Tags:[a,b],Pos:[1.0d],Empty:[]}`
+		srcSNBT = `{Tags:[c],Pos:[1,2],Empty:[x]}`
+	)
+	tests := []struct {
+		strategy ListStrategy
+		want     string
+	}{
+		{ListReplace, `{Tags:[c],Pos:[1,2],Empty:[x]}`},
+		// lists of different element types are replaced
+		{ListAppend, `{Tags:[a,b,c],Pos:[1,2],Empty:[x]}`},
+	}
+	for _, test := range tests {
+		dst := parseCompound(t, dstSNBT)
+		Merge(dst, parseCompound(t, srcSNBT), MergeOptions{ListStrategy: test.strategy})
+		if want := parseCompound(t, test.want); !Equal(dst, want) {
+			t.Errorf("strategy %d: merged %s, want %s", test.strategy, ToSNBT(dst), test.want)
+		}
+		if list, _ := dst.List("Empty"); list.ElementType != NodeTypeString {
+			t.Errorf("strategy %d: element type of appended empty list = %v", test.strategy, list.ElementType)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package nbt
+
+import (
+	"fmt"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// NBT strings use the modified UTF-8 of Java, which encodes NUL as the two
+// bytes 0xC0 0x80 and characters outside the BMP as a surrogate pair of two
+// three-byte sequences.
+
+// decodeModifiedUTF8 converts modified UTF-8 to a Go string. Four-byte
+// sequences of standard UTF-8 are accepted as well, as some tools write them.
+// Unpaired surrogates are replaced by U+FFFD.
+func decodeModifiedUTF8(data []byte) (string, error) {
+	// standard UTF-8 rejects encoded NUL and surrogates, so valid input only
+	// holds characters that are encoded the same
+	if utf8.Valid(data) {
+		return string(data), nil
+	}
+
+	runes := make([]rune, 0, len(data))
+	for i := 0; i < len(data); {
+		r, size, err := decodeModifiedUTF8Char(data[i:])
+		if err != nil {
+			return "", fmt.Errorf("invalid modified UTF-8 at byte %d: %w", i, err)
+		}
+		i += size
+
+		if utf16.IsSurrogate(r) {
+			if r < 0xdc00 && i < len(data) {
+				if low, lowSize, err := decodeModifiedUTF8Char(data[i:]); err == nil {
+					if pair := utf16.DecodeRune(r, low); pair != utf8.RuneError {
+						runes = append(runes, pair)
+						i += lowSize
+						continue
+					}
+				}
+			}
+			r = utf8.RuneError
+		}
+		runes = append(runes, r)
+	}
+	return string(runes), nil
+}
+
+func decodeModifiedUTF8Char(data []byte) (rune, int, error) {
+	b := data[0]
+	switch {
+	case b < 0x80:
+		return rune(b), 1, nil
+	case b&0xe0 == 0xc0:
+		if len(data) < 2 || data[1]&0xc0 != 0x80 {
+			return 0, 0, fmt.Errorf("truncated two-byte sequence")
+		}
+		return rune(b&0x1f)<<6 | rune(data[1]&0x3f), 2, nil
+	case b&0xf0 == 0xe0:
+		if len(data) < 3 || data[1]&0xc0 != 0x80 || data[2]&0xc0 != 0x80 {
+			return 0, 0, fmt.Errorf("truncated three-byte sequence")
+		}
+		return rune(b&0x0f)<<12 | rune(data[1]&0x3f)<<6 | rune(data[2]&0x3f), 3, nil
+	case b&0xf8 == 0xf0:
+		r, size := utf8.DecodeRune(data)
+		if r == utf8.RuneError {
+			return 0, 0, fmt.Errorf("malformed four-byte sequence")
+		}
+		return r, size, nil
+	}
+	return 0, 0, fmt.Errorf("unexpected byte 0x%02x", b)
+}
+
+// encodeModifiedUTF8 converts a Go string to modified UTF-8. Invalid UTF-8 in
+// s is encoded as U+FFFD.
+func encodeModifiedUTF8(s string) []byte {
+	data := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch {
+		case r == 0:
+			data = append(data, 0xc0, 0x80)
+		case r < 0x10000:
+			data = utf8.AppendRune(data, r)
+		default:
+			high, low := utf16.EncodeRune(r)
+			data = appendThreeByteChar(data, high)
+			data = appendThreeByteChar(data, low)
+		}
+	}
+	return data
+}
+
+func appendThreeByteChar(data []byte, r rune) []byte {
+	return append(data, 0xe0|byte(r>>12), 0x80|byte(r>>6)&0x3f, 0x80|byte(r)&0x3f)
+}
@@ -0,0 +1,61 @@
+package nbt
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestModifiedUTF8(t *testing.T) {
+	tests := []struct {
+		str     string
+		encoded []byte
+	}{
+		{"abc", []byte("abc")},
+		{"a\x00b", []byte{'a', 0xc0, 0x80, 'b'}},
+		{"ä€", []byte{0xc3, 0xa4, 0xe2, 0x82, 0xac}},
+		// U+1F600 as the surrogate pair D83D DE00
+		{"x😀", []byte{'x', 0xed, 0xa0, 0xbd, 0xed, 0xb8, 0x80}},
+	}
+	for _, test := range tests {
+		encoded := encodeModifiedUTF8(test.str)
+		if !bytes.Equal(encoded, test.encoded) {
+			t.Errorf("encode %q = %x, want %x", test.str, encoded, test.encoded)
+		}
+		decoded, err := decodeModifiedUTF8(test.encoded)
+		if err != nil {
+			t.Errorf("decode %x: %v", test.encoded, err)
+		} else if decoded != test.str {
+			t.Errorf("decode %x = %q, want %q", test.encoded, decoded, test.str)
+		}
+	}
+
+	// standard UTF-8 written by other tools is accepted as well
+	if decoded, err := decodeModifiedUTF8([]byte("\x00😀")); err != nil || decoded != "\x00😀" {
+		t.Errorf("decode standard UTF-8 = %q, %v", decoded, err)
+	}
+	// an unpaired surrogate is replaced
+	if decoded, err := decodeModifiedUTF8([]byte{0xc0, 0x80, 0xed, 0xa0, 0xbd}); err != nil || decoded != "\x00�" {
+		t.Errorf("decode unpaired surrogate = %q, %v", decoded, err)
+	}
+	if _, err := decodeModifiedUTF8([]byte{0xc0, 0x80, 0xe2, 0x82}); err == nil {
+		t.Errorf("expected error for truncated sequence")
+	}
+}
+
+func TestModifiedUTF8RoundTrip(t *testing.T) {
+	name := "sign\x00text 😀"
+	f := NewFile("")
+	f.Root.(*CompoundNode).Set("name", &StringNode{Value: name})
+	data := encodeFile(t, f)
+	if bytes.Contains(data, []byte{0xf0}) || !bytes.Contains(data, []byte{0xc0, 0x80}) {
+		t.Errorf("string is not written as modified UTF-8: %x", data)
+	}
+
+	readFile, err := ReadFromBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if val, _ := readFile.Root.(*CompoundNode).Str("name"); val != name {
+		t.Errorf("read %q, want %q", val, name)
+	}
+}
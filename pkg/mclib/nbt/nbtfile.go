@@ -3,11 +3,16 @@ package nbt
 import (
 	"bytes"
 	"compress/gzip"
+	"context"
 	"encoding/binary"
 	"fmt"
 	"io"
+	"log/slog"
+	"maps"
 	"math"
 	"os"
+	"slices"
+	"strconv"
 )
 
 const (
@@ -28,21 +33,160 @@ const (
 
 type NodeType byte
 
+var tagNames = map[NodeType]string{
+	NodeTypeEnd:       "TAG_End",
+	NodeTypeByte:      "TAG_Byte",
+	NodeTypeShort:     "TAG_Short",
+	NodeTypeInt:       "TAG_Int",
+	NodeTypeLong:      "TAG_Long",
+	NodeTypeFloat:     "TAG_Float",
+	NodeTypeDouble:    "TAG_Double",
+	NodeTypeByteArray: "TAG_Byte_Array",
+	NodeTypeString:    "TAG_String",
+	NodeTypeList:      "TAG_List",
+	NodeTypeCompound:  "TAG_Compound",
+	NodeTypeIntArray:  "TAG_Int_Array",
+	NodeTypeLongArray: "TAG_Long_Array",
+}
+
+// String returns the name of the type used by the NBT specification, e.g.
+// "TAG_Compound", or "TAG_Unknown(64)" for undefined types.
+func (t NodeType) String() string {
+	if name, ok := tagNames[t]; ok {
+		return name
+	}
+	return "TAG_Unknown(" + strconv.Itoa(int(t)) + ")"
+}
+
+func IsValidNodeType(nodeType NodeType) bool {
+	return nodeType <= NodeTypeLongArray
+}
+
+// IsScalar reports whether nodes of the type hold a single number or string.
+func IsScalar(nodeType NodeType) bool {
+	return (nodeType >= NodeTypeByte && nodeType <= NodeTypeDouble) || nodeType == NodeTypeString
+}
+
+// IsContainer reports whether nodes of the type hold other nodes.
+func IsContainer(nodeType NodeType) bool {
+	return nodeType == NodeTypeList || nodeType == NodeTypeCompound
+}
+
+// IsArray reports whether nodes of the type hold a sequence of numbers.
+func IsArray(nodeType NodeType) bool {
+	return nodeType == NodeTypeByteArray || nodeType == NodeTypeIntArray || nodeType == NodeTypeLongArray
+}
+
 type File struct {
-	Root Node
+	// RootName is the name of the top-level compound, which is usually empty.
+	RootName string
+	Root     Node
+	// Warnings lists non-fatal issues found while reading if
+	// ReadOptions.CollectWarnings is set.
+	Warnings []Warning
+}
+
+// Warning describes a non-fatal issue in the input at the path of the
+// affected node.
+type Warning struct {
+	Path    string
+	Message string
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("%s: %s", w.Path, w.Message)
+}
+
+// NewFile returns a file holding an empty top-level compound of the given name.
+func NewFile(rootName string) *File {
+	return &File{
+		RootName: rootName,
+		Root:     &CompoundNode{Values: make(map[string]Node)},
+	}
+}
+
+// rootCompound returns the top-level compound of the file.
+func (f *File) rootCompound() (*CompoundNode, bool) {
+	rootNode, ok := f.Root.(*CompoundNode)
+	return rootNode, ok
 }
 
 type Node interface {
 	Type() NodeType
 }
 
+// Endianness selects the byte order of numbers in binary NBT.
+type Endianness int
+
+const (
+	// BigEndian is used by Java Edition.
+	BigEndian Endianness = iota
+	// LittleEndian is used by files of Bedrock Edition.
+	LittleEndian
+)
+
+func (e Endianness) byteOrder() binary.ByteOrder {
+	if e == LittleEndian {
+		return binary.LittleEndian
+	}
+	return binary.BigEndian
+}
+
+type ReadOptions struct {
+	// SkipUnknownTags retains tags of unsupported types as UnknownNode instead
+	// of failing the whole read. The first unknown tag ends parsing, see
+	// UnknownNode for the limits this implies.
+	SkipUnknownTags bool
+	// CopyBufferSize is the maximum size of the intermediate buffer used to
+	// decode int and long array payloads. Defaults to DefaultCopyBufferSize.
+	// Byte arrays need no decoding and are read directly into their result.
+	CopyBufferSize int
+	// RejectMultistream fails reading compressed input if a gzip stream
+	// consists of more than one member, which usually indicates corruption.
+	RejectMultistream bool
+	// NormalizeKeys, if set, is applied to every compound key while parsing.
+	// Keys that collide after normalization overwrite each other, so the
+	// value read last is kept.
+	NormalizeKeys func(key string) string
+	// CollectWarnings records non-fatal issues like duplicate keys in
+	// File.Warnings.
+	CollectWarnings bool
+	// MaxTotalNodes limits the number of nodes in the whole tree, which bounds
+	// memory usage for untrusted input. Array elements are not counted. Zero
+	// means no limit.
+	MaxTotalNodes int
+	// NodeFactory creates the nodes of the parsed tree instead of the node
+	// types of this package. Unknown tags are always retained as UnknownNode.
+	NodeFactory NodeFactory
+	// Logger, if set, receives a debug record for every compound child read.
+	Logger *slog.Logger
+	// Endianness is the byte order of the input, BigEndian by default.
+	Endianness Endianness
+	// MaxDepth limits the nesting of lists and compounds below the top-level
+	// compound, which guards against stack exhaustion on malicious input.
+	// Defaults to DefaultMaxDepth.
+	MaxDepth int
+	// MaxElements limits the length of every single list and array. Zero
+	// means no limit. Independent of this limit, memory is only allocated
+	// for elements that are actually present in the input.
+	MaxElements int
+}
+
+const (
+	DefaultCopyBufferSize = 32 * 1024
+	DefaultMaxDepth       = 512
+)
+
 func ReadFromFile(file string) (*File, error) {
+	return ReadFromFileWithOptions(file, ReadOptions{})
+}
+
+func ReadFromFileWithOptions(file string, opts ReadOptions) (*File, error) {
 	rawData, err := os.ReadFile(file)
 	if err != nil {
 		return nil, fmt.Errorf("read file: %w", err)
 	}
-
-	return ReadGZipFromStream(bytes.NewReader(rawData))
+	return ReadFromStreamWithOptions(bytes.NewReader(rawData), opts)
 }
 
 func ReadGZipFromStream(r io.Reader) (*File, error) {
@@ -51,98 +195,426 @@ func ReadGZipFromStream(r io.Reader) (*File, error) {
 		return nil, fmt.Errorf("open gzip reader: %w", err)
 	}
 
-	return ReadFromStream(gzipReader)
+	return ReadRawFromStream(gzipReader)
 }
 
+// ReadFromStream reads NBT data that is either uncompressed or compressed
+// with gzip or zlib. The input is buffered, so r may be read beyond the end
+// of the data.
 func ReadFromStream(r io.Reader) (*File, error) {
-	rootNode, err := readNodeOfType(r, NodeTypeCompound, true)
+	return ReadFromStreamWithOptions(r, ReadOptions{})
+}
+
+func ReadFromStreamWithOptions(r io.Reader, opts ReadOptions) (*File, error) {
+	return readFromStream(context.Background(), r, opts)
+}
+
+// ReadFromStreamContext reads NBT data like ReadFromStream, but stops with the
+// error of ctx once it is cancelled or its deadline is exceeded.
+func ReadFromStreamContext(ctx context.Context, r io.Reader) (*File, error) {
+	f, err := readFromStream(ctx, r, ReadOptions{})
+	if err != nil && ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	return f, err
+}
+
+func readFromStream(ctx context.Context, r io.Reader, opts ReadOptions) (*File, error) {
+	r, err := decompress(r, opts)
+	if err != nil {
+		return nil, err
+	}
+	f, err := readRawFromStream(ctx, r, opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.RejectMultistream {
+		// draining the input reveals any further gzip member
+		if err := drain(r); err != nil {
+			return nil, fmt.Errorf("read trailing data: %w", err)
+		}
+	}
+	return f, nil
+}
+
+// ReadRawFromStream reads uncompressed NBT data without looking for any
+// compression first.
+func ReadRawFromStream(r io.Reader) (*File, error) {
+	return ReadRawFromStreamWithOptions(r, ReadOptions{})
+}
+
+func ReadRawFromStreamWithOptions(r io.Reader, opts ReadOptions) (*File, error) {
+	return readRawFromStream(context.Background(), r, opts)
+}
+
+func readRawFromStream(ctx context.Context, r io.Reader, opts ReadOptions) (*File, error) {
+	return readRaw(&decoder{r: r, order: opts.Endianness.byteOrder(), opts: opts, ctx: ctx})
+}
+
+// ReadFromBytes reads NBT data from memory. Uncompressed data is parsed in
+// place, which avoids the buffering and copying of ReadFromStream. data must
+// not be modified until ReadFromBytes returns, but the result does not keep
+// any reference to it.
+func ReadFromBytes(data []byte) (*File, error) {
+	return ReadFromBytesWithOptions(data, ReadOptions{})
+}
+
+func ReadFromBytesWithOptions(data []byte, opts ReadOptions) (*File, error) {
+	if detectCompression(data) != CompressionNone {
+		return ReadFromStreamWithOptions(bytes.NewReader(data), opts)
+	}
+	if data == nil {
+		data = []byte{}
+	}
+	return readRaw(&decoder{data: data, order: opts.Endianness.byteOrder(), opts: opts})
+}
+
+func readRaw(d *decoder) (*File, error) {
+	opts := d.opts
+	rootNode := &CompoundNode{}
+	rootName, err := d.readRootInto(rootNode)
 	if err != nil {
-		return nil, fmt.Errorf("read nbt data: %w", err)
+		return nil, fmt.Errorf("read nbt data: %w", d.offsetError(err))
 	}
 
+	var root Node = rootNode
+	if opts.NodeFactory != nil {
+		root = opts.NodeFactory.NewCompound(rootNode.keyOrder, rootNode.Values)
+	}
 	return &File{
-		Root: rootNode,
+		RootName: rootName,
+		Root:     root,
+		Warnings: d.warnings,
 	}, nil
 }
 
-func readRawByte(r io.Reader) (byte, error) {
-	val := make([]byte, 1)
-	if _, err := io.ReadFull(r, val); err != nil {
+type decoder struct {
+	r io.Reader
+	// data, if set, is the complete input, which is read instead of r.
+	data  []byte
+	order binary.ByteOrder
+	opts  ReadOptions
+	// truncated is set once an UnknownNode has consumed the remaining input.
+	truncated bool
+	// path of the node currently read, only tracked with CollectWarnings.
+	path     string
+	warnings []Warning
+	// nodeCount is the number of nodes read so far.
+	nodeCount int
+	// varint selects the Bedrock network encoding of ints, longs and lengths.
+	varint bool
+	// depth is the number of lists and compounds enclosing the current node.
+	depth int
+	// ctx, if set, is checked every ctxCheckInterval nodes.
+	ctx context.Context
+	// scratch holds the payload of fixed-width values while decoding them.
+	scratch [8]byte
+	// copyBuf is reused to decode int and long array payloads.
+	copyBuf []byte
+	// offset is the number of bytes consumed from the input.
+	offset int64
+}
+
+const ctxCheckInterval = 1024
+
+// offsetError prefixes err with the number of bytes consumed before it
+// occurred. For compressed input, this is the offset in the decompressed data.
+func (d *decoder) offsetError(err error) error {
+	return fmt.Errorf("at offset %d: %w", d.offset, err)
+}
+
+func (d *decoder) warnf(format string, args ...interface{}) {
+	if d.opts.CollectWarnings {
+		d.warnings = append(d.warnings, Warning{Path: d.path, Message: fmt.Sprintf(format, args...)})
+	}
+}
+
+// ReadFrom implements io.ReaderFrom by replacing f with uncompressed NBT data
+// read from r. It returns the number of bytes consumed and does not read
+// beyond the end of the data, so wrap r in a buffered reader if necessary.
+func (f *File) ReadFrom(r io.Reader) (int64, error) {
+	d := &decoder{r: r, order: binary.BigEndian}
+	result, err := readRaw(d)
+	if err != nil {
+		return d.offset, err
+	}
+	*f = *result
+	return d.offset, nil
+}
+
+// ReadOne reads a single named tag of any type from uncompressed NBT data and
+// leaves r positioned directly after it, so NBT can be embedded in other
+// binary formats.
+func ReadOne(r io.Reader) (Node, string, error) {
+	d := &decoder{r: r, order: binary.BigEndian}
+	nodeType, err := d.readRawNodeType()
+	if err != nil {
+		if err == ErrUnexpectedEOF {
+			err = ErrEmptyInput
+		}
+		return nil, "", fmt.Errorf("read tag: %w", err)
+	}
+	if nodeType == NodeTypeEnd {
+		return nil, "", fmt.Errorf("read tag: unexpected end tag")
+	}
+	name, err := d.readRawString()
+	if err != nil {
+		return nil, "", fmt.Errorf("read tag: %w", d.offsetError(err))
+	}
+	node, err := d.readNodeOfType(nodeType)
+	if err != nil {
+		return nil, "", fmt.Errorf("read tag %q: %w", name, d.offsetError(err))
+	}
+	return node, name, nil
+}
+
+// ReadInto parses uncompressed NBT data into dst, which takes the place of
+// the top-level compound. The map of dst is cleared and reused, so nodes
+// obtained from dst earlier must not be relied upon afterwards; nested nodes
+// are always allocated freshly. On error, dst holds the partially read data.
+func ReadInto(r io.Reader, dst *CompoundNode) error {
+	d := &decoder{r: r, order: binary.BigEndian}
+	if _, err := d.readRootInto(dst); err != nil {
+		return fmt.Errorf("read nbt data: %w", d.offsetError(err))
+	}
+	return nil
+}
+
+// readRootInto reads the header of the top-level compound followed by its
+// children into dst and returns the name of the compound.
+func (d *decoder) readRootInto(dst *CompoundNode) (string, error) {
+	nodeType, err := d.readRawNodeType()
+	if err != nil {
+		if err == ErrUnexpectedEOF {
+			return "", ErrEmptyInput
+		}
+		return "", err
+	}
+	if nodeType != NodeTypeCompound {
+		return "", fmt.Errorf("%w, got type %v", ErrNotCompound, nodeType)
+	}
+	if err := d.countNode(); err != nil {
+		return "", err
+	}
+	name, err := d.readRawString()
+	if err != nil {
+		return "", err
+	}
+
+	if dst.Values == nil {
+		dst.Values = make(map[string]Node)
+	} else {
+		clear(dst.Values)
+	}
+	dst.keyOrder = dst.keyOrder[:0]
+	if err := d.readCompoundChildren(dst); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+func (d *decoder) copyBufferSize() int {
+	if d.opts.CopyBufferSize > 0 {
+		return d.opts.CopyBufferSize
+	}
+	return DefaultCopyBufferSize
+}
+
+// copyBuffer returns a buffer for decoding count elements of elemSize bytes.
+// It holds a whole number of elements and is capped at CopyBufferSize, so
+// small arrays do not allocate a full buffer.
+func (d *decoder) copyBuffer(elemSize, count int) []byte {
+	size := max(min(d.copyBufferSize(), elemSize*count)/elemSize, 1) * elemSize
+	if cap(d.copyBuf) < size {
+		d.copyBuf = make([]byte, size)
+	}
+	return d.copyBuf[:size]
+}
+
+// enterContainer registers another level of nesting and fails once MaxDepth
+// is exceeded. Every successful call must be followed by leaveContainer.
+func (d *decoder) enterContainer() error {
+	maxDepth := DefaultMaxDepth
+	if d.opts.MaxDepth > 0 {
+		maxDepth = d.opts.MaxDepth
+	}
+	if d.depth >= maxDepth {
+		return fmt.Errorf("%w: max nesting depth %d exceeded", ErrDepthExceeded, maxDepth)
+	}
+	d.depth++
+	return nil
+}
+
+func (d *decoder) leaveContainer() {
+	d.depth--
+}
+
+// maxPrealloc is the maximum number of elements allocated ahead of reading
+// them, so a forged length cannot allocate more memory than the input holds.
+const maxPrealloc = 64 * 1024
+
+// checkLength fails if a list or array exceeds MaxElements.
+func (d *decoder) checkLength(length int32) error {
+	if d.opts.MaxElements > 0 && int(length) > d.opts.MaxElements {
+		return fmt.Errorf("%w: length %d exceeds %d", ErrTooManyElements, length, d.opts.MaxElements)
+	}
+	return nil
+}
+
+// readBytes reads length bytes, growing the buffer as the data arrives.
+func (d *decoder) readBytes(length int) ([]byte, error) {
+	if d.data != nil {
+		val, err := d.next(length)
+		if err != nil {
+			return nil, err
+		}
+		return bytes.Clone(val), nil
+	}
+	val := make([]byte, 0, min(length, maxPrealloc))
+	for len(val) < length {
+		n := min(length-len(val), maxPrealloc)
+		val = slices.Grow(val, n)
+		if err := d.readFull(val[len(val) : len(val)+n]); err != nil {
+			return nil, err
+		}
+		val = val[:len(val)+n]
+	}
+	return val, nil
+}
+
+func (d *decoder) readRawByte() (byte, error) {
+	val := d.scratch[:1]
+	if err := d.readFull(val); err != nil {
 		return 0, err
 	}
 	return val[0], nil
 }
 
-func readRawUShort(r io.Reader) (uint16, error) {
-	val := make([]byte, 2)
-	if _, err := io.ReadFull(r, val); err != nil {
+func (d *decoder) readRawUShort() (uint16, error) {
+	val := d.scratch[:2]
+	if err := d.readFull(val); err != nil {
 		return 0, err
 	}
-	return binary.BigEndian.Uint16(val), nil
+	return d.order.Uint16(val), nil
 }
 
-func readRawInt(r io.Reader) (int32, error) {
-	val := make([]byte, 4)
-	if _, err := io.ReadFull(r, val); err != nil {
+func (d *decoder) readRawInt() (int32, error) {
+	if d.varint {
+		return d.readVarint32()
+	}
+	val := d.scratch[:4]
+	if err := d.readFull(val); err != nil {
 		return 0, err
 	}
-	return int32(binary.BigEndian.Uint32(val)), nil
+	return int32(d.order.Uint32(val)), nil
 }
 
-func readRawString(r io.Reader) (string, error) {
-	strLen, err := readRawUShort(r)
-	if err != nil {
-		return "", err
+func (d *decoder) readRawString() (string, error) {
+	var strLen int
+	if d.varint {
+		length, err := d.readVaruint32()
+		if err != nil {
+			return "", err
+		}
+		strLen = int(length)
+	} else {
+		length, err := d.readRawUShort()
+		if err != nil {
+			return "", err
+		}
+		strLen = int(length)
 	}
-	val := make([]byte, strLen)
-	if _, err := io.ReadFull(r, val); err != nil {
+	if d.data != nil {
+		// decoding copies the string anyway
+		val, err := d.next(strLen)
+		if err != nil {
+			return "", err
+		}
+		return decodeModifiedUTF8(val)
+	}
+	val, err := d.readBytes(strLen)
+	if err != nil {
 		return "", err
 	}
-	return string(val), nil
+	return decodeModifiedUTF8(val)
 }
 
-func readRawNodeType(r io.Reader) (NodeType, error) {
-	val, err := readRawByte(r)
+func (d *decoder) readRawNodeType() (NodeType, error) {
+	val, err := d.readRawByte()
 	if err != nil {
 		return 0, err
 	}
-	return NodeType(val), nil
+	nodeType := NodeType(val)
+	if !IsValidNodeType(nodeType) && !d.opts.SkipUnknownTags {
+		return 0, fmt.Errorf("%w %v at offset %d", ErrUnsupportedNodeType, nodeType, d.offset-1)
+	}
+	return nodeType, nil
 }
 
-func readNode(r io.Reader) (Node, error) {
-	nodeType, err := readRawNodeType(r)
+func (d *decoder) readNode() (Node, error) {
+	nodeType, err := d.readRawNodeType()
 	if err != nil {
 		return nil, err
 	}
 
-	return readNodeOfType(r, nodeType, false)
+	return d.readNodeOfType(nodeType)
 }
 
-func readNodeOfType(r io.Reader, nodeType NodeType, isRoot bool) (Node, error) {
+// countNode registers another node and fails once MaxTotalNodes is exceeded
+// or the context is done.
+func (d *decoder) countNode() error {
+	d.nodeCount++
+	if d.opts.MaxTotalNodes > 0 && d.nodeCount > d.opts.MaxTotalNodes {
+		return fmt.Errorf("%w: more than %d", ErrTooManyNodes, d.opts.MaxTotalNodes)
+	}
+	if d.ctx != nil && d.nodeCount%ctxCheckInterval == 0 {
+		return d.ctx.Err()
+	}
+	return nil
+}
+
+func (d *decoder) readNodeOfType(nodeType NodeType) (Node, error) {
+	if err := d.countNode(); err != nil {
+		return nil, err
+	}
+	if IsContainer(nodeType) {
+		if err := d.enterContainer(); err != nil {
+			return nil, err
+		}
+		defer d.leaveContainer()
+	}
+
 	switch nodeType {
 	case NodeTypeByte:
-		return readByteNode(r)
+		return d.readByteNode()
 	case NodeTypeShort:
-		return readShortNode(r)
+		return d.readShortNode()
 	case NodeTypeInt:
-		return readIntNode(r)
+		return d.readIntNode()
 	case NodeTypeLong:
-		return readLongNode(r)
+		return d.readLongNode()
 	case NodeTypeFloat:
-		return readFloatNode(r)
+		return d.readFloatNode()
 	case NodeTypeDouble:
-		return readDoubleNode(r)
+		return d.readDoubleNode()
+	case NodeTypeByteArray:
+		return d.readByteArrayNode()
 	case NodeTypeString:
-		return readStringNode(r)
+		return d.readStringNode()
 	case NodeTypeList:
-		return readListNode(r)
+		return d.readListNode()
 	case NodeTypeCompound:
-		return readCompoundNode(r, isRoot)
+		return d.readCompoundNode()
 	case NodeTypeIntArray:
-		return readIntArrayNode(r)
+		return d.readIntArrayNode()
+	case NodeTypeLongArray:
+		return d.readLongArrayNode()
 
 	default:
-		return nil, fmt.Errorf("unsupported node type %v", nodeType)
+		if d.opts.SkipUnknownTags {
+			return d.readUnknownNode(nodeType)
+		}
+		return nil, fmt.Errorf("%w %v", ErrUnsupportedNodeType, nodeType)
 	}
 }
 
@@ -152,14 +624,12 @@ type ByteNode struct {
 
 func (n *ByteNode) Type() NodeType { return NodeTypeByte }
 
-func readByteNode(r io.Reader) (*ByteNode, error) {
-	val, err := readRawByte(r)
+func (d *decoder) readByteNode() (Node, error) {
+	val, err := d.readRawByte()
 	if err != nil {
 		return nil, err
 	}
-	return &ByteNode{
-		Value: val,
-	}, nil
+	return d.nodes().NewByte(val), nil
 }
 
 type ShortNode struct {
@@ -168,14 +638,12 @@ type ShortNode struct {
 
 func (n *ShortNode) Type() NodeType { return NodeTypeShort }
 
-func readShortNode(r io.Reader) (*ShortNode, error) {
-	val := make([]byte, 2)
-	if _, err := io.ReadFull(r, val); err != nil {
+func (d *decoder) readShortNode() (Node, error) {
+	val := d.scratch[:2]
+	if err := d.readFull(val); err != nil {
 		return nil, err
 	}
-	return &ShortNode{
-		Value: int16(binary.BigEndian.Uint16(val)),
-	}, nil
+	return d.nodes().NewShort(int16(d.order.Uint16(val))), nil
 }
 
 type IntNode struct {
@@ -184,14 +652,12 @@ type IntNode struct {
 
 func (n *IntNode) Type() NodeType { return NodeTypeInt }
 
-func readIntNode(r io.Reader) (*IntNode, error) {
-	val, err := readRawInt(r)
+func (d *decoder) readIntNode() (Node, error) {
+	val, err := d.readRawInt()
 	if err != nil {
 		return nil, err
 	}
-	return &IntNode{
-		Value: val,
-	}, nil
+	return d.nodes().NewInt(val), nil
 }
 
 type LongNode struct {
@@ -200,14 +666,19 @@ type LongNode struct {
 
 func (n *LongNode) Type() NodeType { return NodeTypeLong }
 
-func readLongNode(r io.Reader) (*LongNode, error) {
-	val := make([]byte, 8)
-	if _, err := io.ReadFull(r, val); err != nil {
+func (d *decoder) readLongNode() (Node, error) {
+	if d.varint {
+		val, err := d.readVarint64()
+		if err != nil {
+			return nil, err
+		}
+		return d.nodes().NewLong(val), nil
+	}
+	val := d.scratch[:8]
+	if err := d.readFull(val); err != nil {
 		return nil, err
 	}
-	return &LongNode{
-		Value: int64(binary.BigEndian.Uint64(val)),
-	}, nil
+	return d.nodes().NewLong(int64(d.order.Uint64(val))), nil
 }
 
 type FloatNode struct {
@@ -216,14 +687,12 @@ type FloatNode struct {
 
 func (n *FloatNode) Type() NodeType { return NodeTypeFloat }
 
-func readFloatNode(r io.Reader) (*FloatNode, error) {
-	val := make([]byte, 4)
-	if _, err := io.ReadFull(r, val); err != nil {
+func (d *decoder) readFloatNode() (Node, error) {
+	val := d.scratch[:4]
+	if err := d.readFull(val); err != nil {
 		return nil, err
 	}
-	return &FloatNode{
-		Value: math.Float32frombits(binary.BigEndian.Uint32(val)),
-	}, nil
+	return d.nodes().NewFloat(math.Float32frombits(d.order.Uint32(val))), nil
 }
 
 type DoubleNode struct {
@@ -232,102 +701,274 @@ type DoubleNode struct {
 
 func (n *DoubleNode) Type() NodeType { return NodeTypeDouble }
 
-func readDoubleNode(r io.Reader) (*DoubleNode, error) {
-	val := make([]byte, 8)
-	if _, err := io.ReadFull(r, val); err != nil {
+func (d *decoder) readDoubleNode() (Node, error) {
+	val := d.scratch[:8]
+	if err := d.readFull(val); err != nil {
 		return nil, err
 	}
-	return &DoubleNode{
-		Value: math.Float64frombits(binary.BigEndian.Uint64(val)),
-	}, nil
+	return d.nodes().NewDouble(math.Float64frombits(d.order.Uint64(val))), nil
+}
+
+type ByteArrayNode struct {
+	Values []byte
+}
+
+func (n *ByteArrayNode) Type() NodeType { return NodeTypeByteArray }
+
+// Bytes returns the internal slice without copying. It must not be modified
+// unless the node is meant to change as well.
+func (n *ByteArrayNode) Bytes() []byte { return n.Values }
+
+// BytesCopy returns a copy of the array that is safe to modify.
+func (n *ByteArrayNode) BytesCopy() []byte { return bytes.Clone(n.Values) }
+
+func (d *decoder) readByteArrayNode() (Node, error) {
+	length, err := d.readRawInt()
+	if err != nil {
+		return nil, err
+	}
+	if length < 0 {
+		return nil, fmt.Errorf("%w: negative byte array length %d", ErrInvalidArray, length)
+	}
+	if err := d.checkLength(length); err != nil {
+		return nil, err
+	}
+
+	val, err := d.readBytes(int(length))
+	if err != nil {
+		return nil, err
+	}
+	return d.nodes().NewByteArray(val), nil
 }
 
 type StringNode struct {
 	Value string
 }
 
-func (n *StringNode) Type() NodeType { return NodeTypeInt }
+func (n *StringNode) Type() NodeType { return NodeTypeString }
 
-func readStringNode(r io.Reader) (*StringNode, error) {
-	val, err := readRawString(r)
+func (d *decoder) readStringNode() (Node, error) {
+	val, err := d.readRawString()
 	if err != nil {
 		return nil, err
 	}
-	return &StringNode{
-		Value: val,
-	}, nil
+	return d.nodes().NewString(val), nil
 }
 
 type ListNode struct {
-	Values []Node
+	// ElementType is the type declared for the elements, which is kept for
+	// empty lists. The writer uses the type of the elements if there are any.
+	ElementType NodeType
+	Values      []Node
 }
 
 func (n *ListNode) Type() NodeType { return NodeTypeList }
 
-func readListNode(r io.Reader) (*ListNode, error) {
-	childNodeType, err := readRawNodeType(r)
+func (d *decoder) readListNode() (Node, error) {
+	childNodeType, err := d.readRawNodeType()
 	if err != nil {
 		return nil, err
 	}
 
-	childCount, err := readRawInt(r)
+	childCount, err := d.readRawInt()
 	if err != nil {
 		return nil, err
 	}
-
-	node := ListNode{
-		Values: make([]Node, childCount),
+	if !IsValidNodeType(childNodeType) {
+		return nil, fmt.Errorf("%w: element type %v", ErrInvalidList, childNodeType)
+	}
+	if childNodeType == NodeTypeEnd && childCount > 0 {
+		return nil, fmt.Errorf("%w: %d elements of end type", ErrInvalidList, childCount)
 	}
+	if childCount < 0 {
+		return nil, fmt.Errorf("%w: negative length %d", ErrInvalidList, childCount)
+	}
+	if d.opts.MaxTotalNodes > 0 && int(childCount) > d.opts.MaxTotalNodes-d.nodeCount {
+		// fail before allocating the elements
+		return nil, fmt.Errorf("%w: list of %d elements exceeds %d", ErrTooManyNodes, childCount, d.opts.MaxTotalNodes)
+	}
+	if err := d.checkLength(childCount); err != nil {
+		return nil, err
+	}
+
+	values := make([]Node, 0, min(int(childCount), maxPrealloc))
+	parentPath := d.path
 	for i := range int(childCount) {
-		childNode, err := readNodeOfType(r, childNodeType, false)
+		if d.opts.CollectWarnings {
+			d.path = indexPath(parentPath, i)
+		}
+		childNode, err := d.readNodeOfType(childNodeType)
+		d.path = parentPath
 		if err != nil {
 			return nil, fmt.Errorf("read list index %d: %w", i, err)
 		}
+		if d.truncated {
+			return nil, fmt.Errorf("read list index %d: unknown tag cannot be retained inside a list", i)
+		}
 
-		node.Values = append(node.Values, childNode)
+		values = append(values, childNode)
 	}
-	return &node, nil
+	return d.nodes().NewList(childNodeType, values), nil
 }
 
 type CompoundNode struct {
 	Values map[string]Node
+	// keyOrder holds the keys in the order they were read or added by Set.
+	// It may contain keys that have since been removed from Values.
+	keyOrder []string
 }
 
 func (n *CompoundNode) Type() NodeType { return NodeTypeCompound }
 
-func readCompoundNode(r io.Reader, isRoot bool) (*CompoundNode, error) {
+// SortedKeys returns the keys of the compound in lexicographical order.
+func (n *CompoundNode) SortedKeys() []string {
+	return slices.Sorted(maps.Keys(n.Values))
+}
+
+// Keys returns the keys of the compound in the order they were read or added
+// by Set, followed by keys assigned to Values directly in lexicographical
+// order. The writer emits children in this order, so unmodified data is
+// written back byte for byte.
+func (n *CompoundNode) Keys() []string {
+	keys := make([]string, 0, len(n.Values))
+	seen := make(map[string]bool, len(n.Values))
+	for _, key := range n.keyOrder {
+		if _, ok := n.Values[key]; ok && !seen[key] {
+			keys = append(keys, key)
+			seen[key] = true
+		}
+	}
+	if len(keys) < len(n.Values) {
+		rest := make([]string, 0, len(n.Values)-len(keys))
+		for key := range n.Values {
+			if !seen[key] {
+				rest = append(rest, key)
+			}
+		}
+		slices.Sort(rest)
+		keys = append(keys, rest...)
+	}
+	return keys
+}
+
+// Range calls fn for every child in sorted key order until fn returns false.
+func (n *CompoundNode) Range(fn func(key string, node Node) bool) {
+	for _, key := range n.SortedKeys() {
+		if !fn(key, n.Values[key]) {
+			return
+		}
+	}
+}
+
+// Set stores node at key, replacing any previous child in place. New keys are
+// appended to the key order.
+func (n *CompoundNode) Set(key string, node Node) {
+	if n.Values == nil {
+		n.Values = make(map[string]Node)
+	}
+	if _, exists := n.Values[key]; !exists {
+		n.keyOrder = append(n.keyOrder, key)
+	}
+	n.Values[key] = node
+}
+
+// Delete removes the child at key and reports whether it existed.
+func (n *CompoundNode) Delete(key string) bool {
+	_, exists := n.Values[key]
+	delete(n.Values, key)
+	if i := slices.Index(n.keyOrder, key); i >= 0 {
+		n.keyOrder = slices.Delete(n.keyOrder, i, i+1)
+	}
+	return exists
+}
+
+// The following accessors return the child of the given key if it exists and
+// has the matching type, and the zero value and false otherwise.
+
+func (n *CompoundNode) Byte(key string) (byte, bool) { return Byte(n.Values[key]) }
+
+func (n *CompoundNode) Short(key string) (int16, bool) { return Short(n.Values[key]) }
+
+func (n *CompoundNode) Int(key string) (int32, bool) { return Int(n.Values[key]) }
+
+func (n *CompoundNode) Long(key string) (int64, bool) { return Long(n.Values[key]) }
+
+func (n *CompoundNode) F32(key string) (float32, bool) { return F32(n.Values[key]) }
+
+func (n *CompoundNode) F64(key string) (float64, bool) { return F64(n.Values[key]) }
+
+// Str is named after the package-level helper, as String would suggest the
+// fmt.Stringer interface.
+func (n *CompoundNode) Str(key string) (string, bool) { return Str(n.Values[key]) }
+
+func (n *CompoundNode) Compound(key string) (*CompoundNode, bool) {
+	node, ok := n.Values[key].(*CompoundNode)
+	return node, ok
+}
+
+func (n *CompoundNode) List(key string) (*ListNode, bool) {
+	node, ok := n.Values[key].(*ListNode)
+	return node, ok
+}
+
+func (d *decoder) readCompoundNode() (Node, error) {
 	node := CompoundNode{
 		Values: make(map[string]Node),
 	}
+	if err := d.readCompoundChildren(&node); err != nil {
+		return nil, err
+	}
+	if d.opts.NodeFactory == nil {
+		return &node, nil
+	}
+	return d.opts.NodeFactory.NewCompound(node.keyOrder, node.Values), nil
+}
+
+func (d *decoder) readCompoundChildren(node *CompoundNode) error {
 	for {
-		childNodeType, err := readRawNodeType(r)
+		childNodeType, err := d.readRawNodeType()
 		if err != nil {
-			return nil, err
+			return err
 		}
 
 		if childNodeType == NodeTypeEnd {
 			break
 		}
 
-		childName, err := readRawString(r)
+		childName, err := d.readRawString()
 		if err != nil {
-			return nil, err
+			return err
+		}
+		if d.opts.NormalizeKeys != nil {
+			childName = d.opts.NormalizeKeys(childName)
 		}
-		fmt.Println(childName)
 
-		childNode, err := readNodeOfType(r, childNodeType, false)
+		parentPath := d.path
+		if d.opts.CollectWarnings {
+			d.path = childPath(parentPath, childName)
+		}
+		if d.opts.Logger != nil {
+			d.opts.Logger.Debug("read compound child", "key", childName, "type", childNodeType)
+		}
+		childNode, err := d.readNodeOfType(childNodeType)
 		if err != nil {
-			return nil, fmt.Errorf("read compound child %q: %w", childName, err)
+			return fmt.Errorf("read compound child %q: %w", childName, err)
+		}
+		if _, ok := node.Values[childName]; ok {
+			d.warnf("duplicate key overwrites previous value")
+		} else {
+			node.keyOrder = append(node.keyOrder, childName)
 		}
+		d.path = parentPath
 
 		node.Values[childName] = childNode
 
-		if isRoot {
-			// the root-node only has a single value
+		if d.truncated {
+			// the remaining input, including all end tags, belongs to the unknown node
 			break
 		}
 	}
-	return &node, nil
+	return nil
 }
 
 type IntArrayNode struct {
@@ -336,22 +977,163 @@ type IntArrayNode struct {
 
 func (n *IntArrayNode) Type() NodeType { return NodeTypeIntArray }
 
-func readIntArrayNode(r io.Reader) (*IntArrayNode, error) {
-	childCount, err := readRawInt(r)
+func (d *decoder) readIntArrayNode() (Node, error) {
+	childCount, err := d.readRawInt()
 	if err != nil {
 		return nil, err
 	}
 
-	node := IntArrayNode{
-		Values: make([]Node, childCount),
+	if childCount < 0 {
+		return nil, fmt.Errorf("%w: negative int array length %d", ErrInvalidArray, childCount)
 	}
-	for i := range int(childCount) {
-		childNode, err := readNodeOfType(r, NodeTypeInt, false)
+	if err := d.checkLength(childCount); err != nil {
+		return nil, err
+	}
+
+	if d.opts.NodeFactory == nil {
+		// build the default nodes right away instead of converting the values afterwards
+		values := make([]Node, 0, min(int(childCount), maxPrealloc))
+		if err := d.readIntArrayValues(int(childCount), func(val int32) {
+			values = append(values, &IntNode{Value: val})
+		}); err != nil {
+			return nil, err
+		}
+		return &IntArrayNode{Values: values}, nil
+	}
+	values := make([]int32, 0, min(int(childCount), maxPrealloc))
+	if err := d.readIntArrayValues(int(childCount), func(val int32) {
+		values = append(values, val)
+	}); err != nil {
+		return nil, err
+	}
+	return d.opts.NodeFactory.NewIntArray(values), nil
+}
+
+// readIntArrayValues reads the payload of an int array and passes the values
+// to add in order.
+func (d *decoder) readIntArrayValues(count int, add func(val int32)) error {
+	if d.varint {
+		for i := range count {
+			val, err := d.readVarint32()
+			if err != nil {
+				return fmt.Errorf("read list index %d: %w", i, err)
+			}
+			add(val)
+		}
+		return nil
+	}
+	if d.data != nil {
+		payload, err := d.next(4 * count)
 		if err != nil {
+			return err
+		}
+		for i := range count {
+			add(int32(d.order.Uint32(payload[4*i:])))
+		}
+		return nil
+	}
+	// decode the payload through a scratch buffer instead of reading every value separately
+	scratch := d.copyBuffer(4, count)
+	for i := 0; i < count; {
+		n := min(count-i, len(scratch)/4)
+		if err := d.readFull(scratch[:4*n]); err != nil {
+			return fmt.Errorf("read list index %d: %w", i, err)
+		}
+		for j := range n {
+			add(int32(d.order.Uint32(scratch[4*j:])))
+		}
+		i += n
+	}
+	return nil
+}
+
+type LongArrayNode struct {
+	Values []int64
+}
+
+func (n *LongArrayNode) Type() NodeType { return NodeTypeLongArray }
+
+func (d *decoder) readLongArrayNode() (Node, error) {
+	childCount, err := d.readRawInt()
+	if err != nil {
+		return nil, err
+	}
+	if childCount < 0 {
+		return nil, fmt.Errorf("%w: negative long array length %d", ErrInvalidArray, childCount)
+	}
+	if err := d.checkLength(childCount); err != nil {
+		return nil, err
+	}
+
+	values := make([]int64, 0, min(int(childCount), maxPrealloc))
+	if d.varint {
+		for i := range int(childCount) {
+			val, err := d.readVarint64()
+			if err != nil {
+				return nil, fmt.Errorf("read list index %d: %w", i, err)
+			}
+			values = append(values, val)
+		}
+		return d.nodes().NewLongArray(values), nil
+	}
+	if d.data != nil {
+		payload, err := d.next(8 * int(childCount))
+		if err != nil {
+			return nil, err
+		}
+		for i := range int(childCount) {
+			values = append(values, int64(d.order.Uint64(payload[8*i:])))
+		}
+		return d.nodes().NewLongArray(values), nil
+	}
+	scratch := d.copyBuffer(8, int(childCount))
+	for i := 0; i < int(childCount); {
+		n := min(int(childCount)-i, len(scratch)/8)
+		if err := d.readFull(scratch[:8*n]); err != nil {
 			return nil, fmt.Errorf("read list index %d: %w", i, err)
 		}
+		for j := range n {
+			values = append(values, int64(d.order.Uint64(scratch[8*j:])))
+		}
+		i += n
+	}
+	return d.nodes().NewLongArray(values), nil
+}
+
+// UnknownNode retains a tag of a type this package cannot parse. NBT payloads
+// do not declare their length, so Raw holds all input following the tag header
+// and parsing ends there. The writer emits Raw verbatim and stops, which
+// allows a lossless edit of everything read before the unknown tag.
+//
+// This has limits: everything after the unknown tag, including later
+// siblings and the end tags of all enclosing compounds, is part of Raw and
+// can neither be accessed nor edited. Keys added to an enclosing compound are
+// written before the unknown tag, as Raw must come last, and Raw holds the
+// complete rest of the input in memory. Round trips are thus only lossless
+// for edits of data read before the unknown tag.
+type UnknownNode struct {
+	TagType NodeType
+	Raw     []byte
+}
+
+func (n *UnknownNode) Type() NodeType { return n.TagType }
 
-		node.Values = append(node.Values, childNode)
+func (d *decoder) readUnknownNode(nodeType NodeType) (*UnknownNode, error) {
+	var raw []byte
+	var err error
+	if d.data != nil {
+		raw = bytes.Clone(d.data[d.offset:])
+	} else {
+		raw, err = io.ReadAll(d.r)
 	}
-	return &node, nil
+	d.offset += int64(len(raw))
+	if err != nil {
+		return nil, err
+	}
+	d.truncated = true
+	d.warnf("retained unknown tag type %v and %d following bytes", nodeType, len(raw))
+	return &UnknownNode{
+		TagType: nodeType,
+		Raw:     raw,
+	}, nil
 }
@@ -0,0 +1,812 @@
+package nbt
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestByteArrayBytes(t *testing.T) {
+	n := &ByteArrayNode{Values: []byte{1, 2, 3}}
+
+	aliased := n.Bytes()
+	aliased[0] = 9
+	if n.Values[0] != 9 {
+		t.Errorf("Bytes() does not alias the node values")
+	}
+
+	copied := n.BytesCopy()
+	copied[1] = 9
+	if n.Values[1] != 2 {
+		t.Errorf("BytesCopy() aliases the node values")
+	}
+	if len(copied) != 3 || copied[0] != 9 || copied[2] != 3 {
+		t.Errorf("BytesCopy() = %v", copied)
+	}
+}
+
+// largeArrayFile returns the encoding of a compound holding an int array and
+// a long array with n elements each.
+func largeArrayFile(tb testing.TB, n int) []byte {
+	tb.Helper()
+	ints := make([]Node, n)
+	longs := make([]int64, n)
+	for i := range n {
+		ints[i] = &IntNode{Value: int32(i)}
+		longs[i] = -int64(i) << 20
+	}
+	f := NewFile("")
+	f.Root.(*CompoundNode).Set("ints", &IntArrayNode{Values: ints})
+	f.Root.(*CompoundNode).Set("longs", &LongArrayNode{Values: longs})
+
+	var buf bytes.Buffer
+	if err := WriteToStream(&buf, f); err != nil {
+		tb.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestCopyBufferSize(t *testing.T) {
+	data := largeArrayFile(t, 1000)
+	want, err := ReadFromBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// sizes below the element width and not divisible by it still work
+	for _, size := range []int{1, 5, 12, 4096} {
+		f, err := ReadRawFromStreamWithOptions(bytes.NewReader(data), ReadOptions{CopyBufferSize: size})
+		if err != nil {
+			t.Fatalf("buffer size %d: %v", size, err)
+		}
+		if !Equal(f.Root, want.Root) {
+			t.Errorf("buffer size %d: arrays differ", size)
+		}
+	}
+}
+
+func TestCopyBufferSmallArrays(t *testing.T) {
+	d := &decoder{}
+	if buf := d.copyBuffer(4, 4); len(buf) != 16 {
+		t.Errorf("buffer for 4 ints has %d bytes, want 16", len(buf))
+	}
+	if buf := d.copyBuffer(8, 1<<20); len(buf) != DefaultCopyBufferSize {
+		t.Errorf("buffer for 1M longs has %d bytes, want %d", len(buf), DefaultCopyBufferSize)
+	}
+	// the larger buffer is reused for later arrays
+	small := d.copyBuffer(4, 4)
+	if len(small) != 16 || cap(small) != DefaultCopyBufferSize {
+		t.Errorf("buffer has len %d and cap %d, want reuse of the previous buffer", len(small), cap(small))
+	}
+}
+
+func BenchmarkCopyBufferSize(b *testing.B) {
+	data := largeArrayFile(b, 1<<18)
+	for _, size := range []int{512, 4 * 1024, DefaultCopyBufferSize, 256 * 1024} {
+		b.Run(fmt.Sprintf("%d", size), func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+			for range b.N {
+				if _, err := ReadRawFromStreamWithOptions(bytes.NewReader(data), ReadOptions{CopyBufferSize: size}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestReadInto(t *testing.T) {
+	f, err := ParseSNBT(`{b:2,a:{x:1b},c:"text"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := WriteToStream(&buf, f); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := &CompoundNode{}
+	dst.Set("stale", &IntNode{})
+	values := dst.Values
+	if err := ReadInto(bytes.NewReader(buf.Bytes()), dst); err != nil {
+		t.Fatal(err)
+	}
+	if !Equal(dst, f.Root) {
+		t.Errorf("ReadInto() = %v, want %v", dst, f.Root)
+	}
+	if fmt.Sprintf("%p", dst.Values) != fmt.Sprintf("%p", values) {
+		t.Errorf("map of dst was not reused")
+	}
+	if keys := dst.Keys(); !slices.Equal(keys, []string{"b", "a", "c"}) {
+		t.Errorf("Keys() = %v, want file order", keys)
+	}
+
+	if err := ReadInto(bytes.NewReader([]byte{0x08, 0x00, 0x00}), dst); err == nil {
+		t.Errorf("expected error for non-compound root")
+	}
+}
+
+func BenchmarkReadInto(b *testing.B) {
+	data := benchmarkFile(b)
+
+	b.Run("ReadInto", func(b *testing.B) {
+		b.ReportAllocs()
+		dst := &CompoundNode{}
+		for range b.N {
+			if err := ReadInto(bytes.NewReader(data), dst); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("ReadFromStream", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			if _, err := ReadFromStream(bytes.NewReader(data)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// benchmarkFile returns the uncompressed encoding of a file resembling a
+// small level.dat, with mostly scalar values.
+func benchmarkFile(tb testing.TB) []byte {
+	tb.Helper()
+	f := NewFile("")
+	data := &CompoundNode{}
+	f.Root.(*CompoundNode).Set("Data", data)
+	for i := range 50 {
+		data.Set(fmt.Sprintf("Int%d", i), &IntNode{Value: int32(i)})
+		data.Set(fmt.Sprintf("Name%d", i), &StringNode{Value: fmt.Sprintf("value %d", i)})
+	}
+	rules := &CompoundNode{}
+	for i := range 20 {
+		rules.Set(fmt.Sprintf("rule%d", i), &StringNode{Value: "true"})
+	}
+	data.Set("GameRules", rules)
+	data.Set("Pos", &ListNode{ElementType: NodeTypeDouble, Values: []Node{&DoubleNode{Value: 1}, &DoubleNode{Value: 2}, &DoubleNode{Value: 3}}})
+
+	var buf bytes.Buffer
+	if err := WriteToStream(&buf, f); err != nil {
+		tb.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestNormalizeKeys(t *testing.T) {
+	f, err := ParseSNBT(`{Data:{LevelName:"x",Player:{Health:20.0f}},DATA:{}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := encodeFile(t, f)
+
+	readFile, err := ReadFromBytesWithOptions(data, ReadOptions{NormalizeKeys: strings.ToLower})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// DATA collides with Data and is read last
+	want, err := ParseSNBT(`{data:{}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Equal(readFile.Root, want.Root) {
+		t.Errorf("read %v, want %v", ToSNBT(readFile.Root), ToSNBT(want.Root))
+	}
+
+	f.Root.(*CompoundNode).Delete("DATA")
+	readFile, err = ReadRawFromStreamWithOptions(bytes.NewReader(encodeFile(t, f)), ReadOptions{NormalizeKeys: strings.ToLower})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nodes := readFile.Query("data.player.health").Nodes(); len(nodes) != 1 {
+		t.Errorf("nested keys are not normalized: %v", ToSNBT(readFile.Root))
+	}
+}
+
+func TestReadOne(t *testing.T) {
+	// a named int tag followed by framing of the embedding format
+	data := []byte{0x03, 0x00, 0x02, 'i', 'd', 0x00, 0x00, 0x01, 0x00, 0xca, 0xfe}
+	r := bytes.NewReader(data)
+	node, name, err := ReadOne(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "id" {
+		t.Errorf("name = %q, want id", name)
+	}
+	if val, ok := Int(node); !ok || val != 256 {
+		t.Errorf("node = %v, want TAG_Int 256", node)
+	}
+	if rest, _ := io.ReadAll(r); !bytes.Equal(rest, []byte{0xca, 0xfe}) {
+		t.Errorf("remaining input = %x, want cafe", rest)
+	}
+
+	// compounds are read including their end tag
+	f := testFile(t)
+	r = bytes.NewReader(append(encodeFile(t, f), 0x42))
+	node, _, err = ReadOne(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Equal(node, f.Root) {
+		t.Errorf("ReadOne() = %v, want %v", node, f.Root)
+	}
+	if r.Len() != 1 {
+		t.Errorf("%d bytes left, want 1", r.Len())
+	}
+
+	if _, _, err := ReadOne(bytes.NewReader(nil)); !errors.Is(err, ErrEmptyInput) {
+		t.Errorf("empty input: %v, want ErrEmptyInput", err)
+	}
+	if _, _, err := ReadOne(bytes.NewReader([]byte{0x00})); err == nil {
+		t.Errorf("expected error for end tag")
+	}
+}
+
+func TestReadNamedRoot(t *testing.T) {
+	// "root" holding three children of different types
+	data := []byte{
+		0x0a, 0x00, 0x04, 'r', 'o', 'o', 't',
+		0x01, 0x00, 0x01, 'a', 0x05,
+		0x08, 0x00, 0x01, 'b', 0x00, 0x02, 'h', 'i',
+		0x0a, 0x00, 0x01, 'c',
+		0x03, 0x00, 0x01, 'd', 0x00, 0x00, 0x00, 0x07,
+		0x00,
+		0x00,
+	}
+	for name, read := range map[string]func([]byte) (*File, error){
+		"bytes":  ReadFromBytes,
+		"stream": func(data []byte) (*File, error) { return ReadFromStream(bytes.NewReader(data)) },
+	} {
+		f, err := read(data)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if f.RootName != "root" {
+			t.Errorf("%s: root name = %q, want root", name, f.RootName)
+		}
+		root := f.Root.(*CompoundNode)
+		if keys := root.Keys(); !slices.Equal(keys, []string{"a", "b", "c"}) {
+			t.Errorf("%s: children = %v, want [a b c]", name, keys)
+		}
+		if val, _ := root.Values["c"].(*CompoundNode).Int("d"); val != 7 {
+			t.Errorf("%s: c.d = %d, want 7", name, val)
+		}
+
+		var buf bytes.Buffer
+		if err := WriteToStream(&buf, f); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buf.Bytes(), data) {
+			t.Errorf("%s: written %x, want %x", name, buf.Bytes(), data)
+		}
+	}
+}
+
+func TestCollectWarnings(t *testing.T) {
+	// {Data:{a:1,a:2}}
+	data := []byte{
+		0x0a, 0x00, 0x00,
+		0x0a, 0x00, 0x04, 'D', 'a', 't', 'a',
+		0x03, 0x00, 0x01, 'a', 0x00, 0x00, 0x00, 0x01,
+		0x03, 0x00, 0x01, 'a', 0x00, 0x00, 0x00, 0x02,
+		0x00,
+		0x00,
+	}
+	f, err := ReadFromBytesWithOptions(data, ReadOptions{CollectWarnings: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Warning{{Path: "Data.a", Message: "duplicate key overwrites previous value"}}
+	if !slices.Equal(f.Warnings, want) {
+		t.Errorf("warnings = %v, want %v", f.Warnings, want)
+	}
+	if val, _ := f.Root.(*CompoundNode).Values["Data"].(*CompoundNode).Int("a"); val != 2 {
+		t.Errorf("a = %d, want the value read last", val)
+	}
+	if keys := f.Root.(*CompoundNode).Values["Data"].(*CompoundNode).Keys(); len(keys) != 1 {
+		t.Errorf("keys = %v, want a single key", keys)
+	}
+
+	f, err = ReadFromBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Warnings) != 0 {
+		t.Errorf("warnings collected without CollectWarnings: %v", f.Warnings)
+	}
+}
+
+func TestMaxTotalNodes(t *testing.T) {
+	// root, Data, LevelName, Version, Id, Values, Pos and two list elements
+	data := encodeFile(t, testFile(t))
+	if _, err := ReadFromBytesWithOptions(data, ReadOptions{MaxTotalNodes: 9}); err != nil {
+		t.Errorf("read with exact node limit: %v", err)
+	}
+	for _, limit := range []int{1, 5, 8} {
+		_, err := ReadFromBytesWithOptions(data, ReadOptions{MaxTotalNodes: limit})
+		if !errors.Is(err, ErrTooManyNodes) {
+			t.Errorf("limit %d: %v, want ErrTooManyNodes", limit, err)
+		}
+	}
+
+	// a list declaring more elements than allowed fails before reading them
+	list := []byte{
+		0x0a, 0x00, 0x00,
+		0x09, 0x00, 0x01, 'l', 0x01, 0x7f, 0xff, 0xff, 0xff,
+	}
+	_, err := ReadRawFromStreamWithOptions(bytes.NewReader(list), ReadOptions{MaxTotalNodes: 100})
+	if !errors.Is(err, ErrTooManyNodes) {
+		t.Errorf("long list: %v, want ErrTooManyNodes", err)
+	}
+}
+
+func TestNodeTypePredicates(t *testing.T) {
+	// scalar, container and array classification of the types 0 to 12
+	tests := []struct {
+		nodeType                   NodeType
+		scalar, container, isArray bool
+	}{
+		{NodeTypeEnd, false, false, false},
+		{NodeTypeByte, true, false, false},
+		{NodeTypeShort, true, false, false},
+		{NodeTypeInt, true, false, false},
+		{NodeTypeLong, true, false, false},
+		{NodeTypeFloat, true, false, false},
+		{NodeTypeDouble, true, false, false},
+		{NodeTypeByteArray, false, false, true},
+		{NodeTypeString, true, false, false},
+		{NodeTypeList, false, true, false},
+		{NodeTypeCompound, false, true, false},
+		{NodeTypeIntArray, false, false, true},
+		{NodeTypeLongArray, false, false, true},
+		{NodeTypeLongArray + 1, false, false, false},
+	}
+	for _, test := range tests {
+		if IsScalar(test.nodeType) != test.scalar {
+			t.Errorf("IsScalar(%v) = %v", test.nodeType, !test.scalar)
+		}
+		if IsContainer(test.nodeType) != test.container {
+			t.Errorf("IsContainer(%v) = %v", test.nodeType, !test.container)
+		}
+		if IsArray(test.nodeType) != test.isArray {
+			t.Errorf("IsArray(%v) = %v", test.nodeType, !test.isArray)
+		}
+	}
+}
+
+func TestNewFileRoundTrip(t *testing.T) {
+	f := NewFile("level")
+	if err := f.Query("").Set("Name", "built").Set("Count", byte(3)).Err(); err != nil {
+		t.Fatal(err)
+	}
+	f.Root.(*CompoundNode).Set("Pos", &ListNode{ElementType: NodeTypeDouble, Values: []Node{&DoubleNode{Value: 1}, &DoubleNode{Value: 2}}})
+
+	var buf bytes.Buffer
+	if err := WriteGZipToStream(&buf, f); err != nil {
+		t.Fatal(err)
+	}
+	readFile, err := ReadFromStream(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if readFile.RootName != "level" {
+		t.Errorf("root name = %q, want level", readFile.RootName)
+	}
+	root := readFile.Root.(*CompoundNode)
+	if name, _ := root.Str("Name"); name != "built" {
+		t.Errorf("Name = %q, want built", name)
+	}
+	if count, _ := root.Byte("Count"); count != 3 {
+		t.Errorf("Count = %d, want 3", count)
+	}
+	if keys := root.Keys(); !slices.Equal(keys, []string{"Name", "Count", "Pos"}) {
+		t.Errorf("keys = %v, want insertion order", keys)
+	}
+	if !Equal(readFile.Root, f.Root) {
+		t.Errorf("read %s, want %s", ToSNBT(readFile.Root), ToSNBT(f.Root))
+	}
+}
+
+func TestCompoundSortedIteration(t *testing.T) {
+	n := &CompoundNode{}
+	for _, key := range []string{"zeta", "Alpha", "beta", "alpha", "_x", "10", "9"} {
+		n.Set(key, &IntNode{})
+	}
+	want := []string{"10", "9", "Alpha", "_x", "alpha", "beta", "zeta"}
+	if keys := n.SortedKeys(); !slices.Equal(keys, want) {
+		t.Errorf("SortedKeys() = %v, want %v", keys, want)
+	}
+
+	var ranged []string
+	n.Range(func(key string, node Node) bool {
+		if node != n.Values[key] {
+			t.Errorf("Range passes wrong node for %s", key)
+		}
+		ranged = append(ranged, key)
+		return true
+	})
+	if !slices.Equal(ranged, want) {
+		t.Errorf("Range order = %v, want %v", ranged, want)
+	}
+
+	ranged = ranged[:0]
+	n.Range(func(key string, _ Node) bool {
+		ranged = append(ranged, key)
+		return len(ranged) < 3
+	})
+	if !slices.Equal(ranged, want[:3]) {
+		t.Errorf("Range after stop = %v, want %v", ranged, want[:3])
+	}
+}
+
+func TestReadByteArrays(t *testing.T) {
+	// {empty:[B;],large:[B;...] with 1024 elements}
+	data := []byte{0x0a, 0x00, 0x00}
+	data = append(data, 0x07, 0x00, 0x05, 'e', 'm', 'p', 't', 'y', 0x00, 0x00, 0x00, 0x00)
+	data = append(data, 0x07, 0x00, 0x05, 'l', 'a', 'r', 'g', 'e', 0x00, 0x00, 0x04, 0x00)
+	for i := range 1024 {
+		data = append(data, byte(i))
+	}
+	data = append(data, 0x00)
+
+	f, err := ReadFromBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := f.Root.(*CompoundNode)
+	empty, ok := root.Values["empty"].(*ByteArrayNode)
+	if !ok || empty.Type() != NodeTypeByteArray || len(empty.Values) != 0 {
+		t.Errorf("empty = %v", root.Values["empty"])
+	}
+	large, ok := root.Values["large"].(*ByteArrayNode)
+	if !ok || large.Type() != NodeTypeByteArray || len(large.Values) != 1024 {
+		t.Fatalf("large = %v", root.Values["large"])
+	}
+	for i, val := range large.Values {
+		if val != byte(i) {
+			t.Fatalf("large[%d] = %d, want %d", i, val, byte(i))
+		}
+	}
+}
+
+func TestLongArrayNegativeValues(t *testing.T) {
+	values := []int64{-1, -2, math.MinInt64, math.MaxInt64, 0, -(1 << 40)}
+	f := NewFile("")
+	f.Root.(*CompoundNode).Set("longs", &LongArrayNode{Values: values})
+
+	var buf bytes.Buffer
+	if err := WriteToStream(&buf, f); err != nil {
+		t.Fatal(err)
+	}
+	// -1 is stored as all bits set, big-endian
+	if !bytes.Contains(buf.Bytes(), []byte{0x00, 0x00, 0x00, 0x06, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}) {
+		t.Errorf("encoding %x does not contain -1 as all ones", buf.Bytes())
+	}
+
+	readFile, err := ReadFromBytes(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	longs, ok := readFile.Root.(*CompoundNode).Values["longs"].(*LongArrayNode)
+	if !ok || longs.Type() != NodeTypeLongArray {
+		t.Fatalf("longs = %v", readFile.Root.(*CompoundNode).Values["longs"])
+	}
+	if !slices.Equal(longs.Values, values) {
+		t.Errorf("read %v, want %v", longs.Values, values)
+	}
+}
+
+func TestStringNodeType(t *testing.T) {
+	if got := (&StringNode{}).Type(); got != NodeTypeString {
+		t.Errorf("StringNode.Type() = %v, want %v", got, NodeTypeString)
+	}
+}
+
+func TestReadPrintsNothing(t *testing.T) {
+	data := encodeFile(t, testFile(t))
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	_, readErr := ReadFromBytes(data)
+	os.Stdout = stdout
+	w.Close()
+	output, err := io.ReadAll(r)
+	r.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+	if len(output) != 0 {
+		t.Errorf("parsing printed %q", output)
+	}
+
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	if _, err := ReadFromBytesWithOptions(data, ReadOptions{Logger: logger}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(logs.String(), "key=LevelName") {
+		t.Errorf("logger did not receive the compound children:\n%s", logs.String())
+	}
+}
+
+func TestCompoundAccessors(t *testing.T) {
+	f, err := ParseSNBT(`{b:1b,s:2s,i:3,l:4L,f:0.5f,d:1.5d,str:"x",c:{},list:[1,2]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := f.Root.(*CompoundNode)
+
+	if val, ok := root.Byte("b"); !ok || val != 1 {
+		t.Errorf("Byte = %v, %v", val, ok)
+	}
+	if val, ok := root.Short("s"); !ok || val != 2 {
+		t.Errorf("Short = %v, %v", val, ok)
+	}
+	if val, ok := root.Int("i"); !ok || val != 3 {
+		t.Errorf("Int = %v, %v", val, ok)
+	}
+	if val, ok := root.Long("l"); !ok || val != 4 {
+		t.Errorf("Long = %v, %v", val, ok)
+	}
+	if val, ok := root.F32("f"); !ok || val != 0.5 {
+		t.Errorf("F32 = %v, %v", val, ok)
+	}
+	if val, ok := root.F64("d"); !ok || val != 1.5 {
+		t.Errorf("F64 = %v, %v", val, ok)
+	}
+	if val, ok := root.Str("str"); !ok || val != "x" {
+		t.Errorf("Str = %v, %v", val, ok)
+	}
+	if val, ok := root.Compound("c"); !ok || val == nil {
+		t.Errorf("Compound = %v, %v", val, ok)
+	}
+	if val, ok := root.List("list"); !ok || len(val.Values) != 2 {
+		t.Errorf("List = %v, %v", val, ok)
+	}
+
+	// absent and mismatching keys return the zero value
+	for _, key := range []string{"missing", "str"} {
+		if val, ok := root.Int(key); ok || val != 0 {
+			t.Errorf("Int(%q) = %v, %v", key, val, ok)
+		}
+		if val, ok := root.Compound(key); ok || val != nil {
+			t.Errorf("Compound(%q) = %v, %v", key, val, ok)
+		}
+		if val, ok := root.List(key); ok || val != nil {
+			t.Errorf("List(%q) = %v, %v", key, val, ok)
+		}
+	}
+	for _, key := range []string{"missing", "i"} {
+		if val, ok := root.Str(key); ok || val != "" {
+			t.Errorf("Str(%q) = %q, %v", key, val, ok)
+		}
+	}
+	if val, ok := root.Short("b"); ok || val != 0 {
+		t.Errorf("Short of byte = %v, %v", val, ok)
+	}
+}
+
+func TestReadLevelDatBuffered(t *testing.T) {
+	data := readGZipFile(t, "testdata/level.dat")
+	want, err := ReadFromStream(iotest.OneByteReader(bytes.NewReader(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := ReadFromStream(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Equal(f.Root, want.Root) {
+		t.Errorf("buffered read differs from byte-wise read")
+	}
+
+	// the allocations depend on the number of nodes, not the number of bytes
+	nodes := 0
+	for _, count := range Stats(f.Root) {
+		nodes += count
+	}
+	reads := map[string]func() error{
+		"ReadFromBytes": func() error {
+			_, err := ReadFromBytes(data)
+			return err
+		},
+		"ReadFromStream": func() error {
+			_, err := ReadFromStream(bytes.NewReader(data))
+			return err
+		},
+	}
+	for name, read := range reads {
+		allocs := testing.AllocsPerRun(20, func() {
+			if err := read(); err != nil {
+				t.Fatal(err)
+			}
+		})
+		if int(allocs) > 4*nodes+64 {
+			t.Errorf("%s: %.0f allocations for %d nodes in %d bytes", name, allocs, nodes, len(data))
+		}
+	}
+}
+
+func BenchmarkReadLevelDat(b *testing.B) {
+	compressed, err := os.ReadFile("testdata/level.dat")
+	if err != nil {
+		b.Fatal(err)
+	}
+	data := readGZipFile(b, "testdata/level.dat")
+
+	b.Run("gzip", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			if _, err := ReadFromStream(bytes.NewReader(compressed)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("raw", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			if _, err := ReadFromStream(bytes.NewReader(data)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestCompoundSetDelete(t *testing.T) {
+	var n CompoundNode
+	n.Set("a", &IntNode{Value: 1})
+	n.Set("b", &IntNode{Value: 2})
+	n.Set("a", &IntNode{Value: 3})
+	if val, _ := n.Int("a"); val != 3 || len(n.Values) != 2 {
+		t.Errorf("after Set: %v", n.Values)
+	}
+
+	if n.Delete("missing") {
+		t.Errorf("Delete of absent key reports true")
+	}
+	if !n.Delete("a") {
+		t.Errorf("Delete of present key reports false")
+	}
+	if n.Delete("a") {
+		t.Errorf("second Delete of key reports true")
+	}
+	if _, ok := n.Values["a"]; ok || len(n.Values) != 1 {
+		t.Errorf("after Delete: %v", n.Values)
+	}
+	// a deleted and added again key moves to the end
+	n.Set("a", &IntNode{Value: 4})
+	if keys := n.Keys(); !slices.Equal(keys, []string{"b", "a"}) {
+		t.Errorf("keys = %v, want [b a]", keys)
+	}
+}
+
+func TestReadFromBytesMatchesStream(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"level.dat": readGZipFile(t, "testdata/level.dat"),
+		"arrays":    largeArrayFile(t, 1000),
+	} {
+		want, err := ReadFromStream(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, err := ReadFromBytes(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if f.RootName != want.RootName || !Equal(f.Root, want.Root) {
+			t.Errorf("%s: trees differ", name)
+		}
+		if !slices.Equal(f.Root.(*CompoundNode).Keys(), want.Root.(*CompoundNode).Keys()) {
+			t.Errorf("%s: key order differs", name)
+		}
+
+		// the tree must not alias the input
+		clear(data)
+		if !Equal(f.Root, want.Root) {
+			t.Errorf("%s: tree changes with the input slice", name)
+		}
+	}
+}
+
+func BenchmarkReadFromBytes(b *testing.B) {
+	data := readGZipFile(b, "testdata/level.dat")
+
+	b.Run("ReadFromBytes", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			if _, err := ReadFromBytes(data); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("ReadFromStream", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			if _, err := ReadFromStream(bytes.NewReader(data)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestReadKeyOrder(t *testing.T) {
+	// {z:1b,a:{y:2b,b:3b},m:4b}
+	data := []byte{
+		0x0a, 0x00, 0x00,
+		0x01, 0x00, 0x01, 'z', 0x01,
+		0x0a, 0x00, 0x01, 'a',
+		0x01, 0x00, 0x01, 'y', 0x02,
+		0x01, 0x00, 0x01, 'b', 0x03,
+		0x00,
+		0x01, 0x00, 0x01, 'm', 0x04,
+		0x00,
+	}
+	for _, read := range []func() (*File, error){
+		func() (*File, error) { return ReadFromBytes(data) },
+		func() (*File, error) { return ReadFromStream(bytes.NewReader(data)) },
+	} {
+		f, err := read()
+		if err != nil {
+			t.Fatal(err)
+		}
+		root := f.Root.(*CompoundNode)
+		if keys := root.Keys(); !slices.Equal(keys, []string{"z", "a", "m"}) {
+			t.Errorf("keys = %v, want [z a m]", keys)
+		}
+		nested, _ := root.Compound("a")
+		if keys := nested.Keys(); !slices.Equal(keys, []string{"y", "b"}) {
+			t.Errorf("nested keys = %v, want [y b]", keys)
+		}
+
+		var buf bytes.Buffer
+		if err := WriteToStream(&buf, f); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buf.Bytes(), data) {
+			t.Errorf("written %x, want %x", buf.Bytes(), data)
+		}
+		if dump := f.String(); strings.Index(dump, `"z"`) > strings.Index(dump, `"a"`) || strings.Index(dump, `"a"`) > strings.Index(dump, `"m"`) {
+			t.Errorf("dump is not in file order:\n%s", dump)
+		}
+	}
+}
+
+func TestNodeTypeString(t *testing.T) {
+	names := []string{
+		"TAG_End", "TAG_Byte", "TAG_Short", "TAG_Int", "TAG_Long", "TAG_Float", "TAG_Double",
+		"TAG_Byte_Array", "TAG_String", "TAG_List", "TAG_Compound", "TAG_Int_Array", "TAG_Long_Array",
+	}
+	if len(names) != int(NodeTypeLongArray)+1 {
+		t.Fatalf("%d names for %d node types", len(names), NodeTypeLongArray+1)
+	}
+	for i, name := range names {
+		if str := NodeType(i).String(); str != name {
+			t.Errorf("NodeType(%d) = %s, want %s", i, str, name)
+		}
+	}
+	if str := NodeType(64).String(); str != "TAG_Unknown(64)" {
+		t.Errorf("NodeType(64) = %s, want TAG_Unknown(64)", str)
+	}
+	if str := fmt.Sprintf("%v", NodeTypeLongArray+1); str != "TAG_Unknown(13)" {
+		t.Errorf("formatted NodeType(13) = %s, want TAG_Unknown(13)", str)
+	}
+
+	_, err := ReadFromBytes([]byte{0x0a, 0x00, 0x00, 0x40})
+	if err == nil || !strings.Contains(err.Error(), "TAG_Unknown(64)") {
+		t.Errorf("error %v does not name TAG_Unknown(64)", err)
+	}
+}
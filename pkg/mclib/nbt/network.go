@@ -0,0 +1,55 @@
+package nbt
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// ReadBedrockNetworkFromStream reads NBT in the encoding of the Bedrock
+// network protocol, which is little-endian but stores ints, longs and all
+// lengths as varints. Signed values use the zigzag mapping, string lengths do
+// not. r is read byte-wise and not beyond the end of the data, so wrap it in a
+// buffered reader if necessary.
+func ReadBedrockNetworkFromStream(r io.Reader) (*File, error) {
+	d := &decoder{r: r, order: binary.LittleEndian, varint: true}
+	rootNode := &CompoundNode{}
+	rootName, err := d.readRootInto(rootNode)
+	if err != nil {
+		return nil, fmt.Errorf("read nbt data: %w", d.offsetError(err))
+	}
+	return &File{
+		RootName: rootName,
+		Root:     rootNode,
+	}, nil
+}
+
+func (d *decoder) readVaruint(maxBits int) (uint64, error) {
+	var val uint64
+	for shift := 0; shift < maxBits; shift += 7 {
+		b, err := d.readRawByte()
+		if err != nil {
+			return 0, err
+		}
+		val |= uint64(b&0x7f) << shift
+		if b&0x80 == 0 {
+			return val, nil
+		}
+	}
+	return 0, fmt.Errorf("varint exceeds %d bits", maxBits)
+}
+
+func (d *decoder) readVaruint32() (uint32, error) {
+	val, err := d.readVaruint(32)
+	return uint32(val), err
+}
+
+func (d *decoder) readVarint32() (int32, error) {
+	val, err := d.readVaruint(32)
+	return int32(val>>1) ^ -int32(val&1), err
+}
+
+func (d *decoder) readVarint64() (int64, error) {
+	val, err := d.readVaruint(64)
+	return int64(val>>1) ^ -int64(val&1), err
+}
@@ -0,0 +1,79 @@
+package nbt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"strings"
+	"testing"
+)
+
+func zigzag(val int64) uint64 {
+	return uint64(val<<1) ^ uint64(val>>63)
+}
+
+func appendNetworkName(data []byte, nodeType NodeType, name string) []byte {
+	data = append(data, byte(nodeType))
+	data = binary.AppendUvarint(data, uint64(len(name)))
+	return append(data, name...)
+}
+
+func TestReadBedrockNetwork(t *testing.T) {
+	ints := []int32{-1, 10000, math.MinInt32, math.MaxInt32}
+	// zigzag encoded, the ints take 1, 3, 5 and 5 bytes
+	for i, size := range []int{1, 3, 5, 5} {
+		if n := len(binary.AppendUvarint(nil, zigzag(int64(ints[i])))); n != size {
+			t.Fatalf("%d is encoded in %d bytes, want %d", ints[i], n, size)
+		}
+	}
+	longStr := strings.Repeat("x", 200)
+
+	data := appendNetworkName(nil, NodeTypeCompound, "")
+	for i, val := range ints {
+		data = appendNetworkName(data, NodeTypeInt, string(rune('a'+i)))
+		data = binary.AppendUvarint(data, zigzag(int64(val)))
+	}
+	data = appendNetworkName(data, NodeTypeLong, "long")
+	data = binary.AppendUvarint(data, zigzag(math.MinInt64))
+	data = appendNetworkName(data, NodeTypeString, "str")
+	data = binary.AppendUvarint(data, uint64(len(longStr)))
+	data = append(data, longStr...)
+	data = appendNetworkName(data, NodeTypeIntArray, "ints")
+	data = binary.AppendUvarint(data, zigzag(2))
+	data = binary.AppendUvarint(data, zigzag(-3))
+	data = binary.AppendUvarint(data, zigzag(300))
+	data = appendNetworkName(data, NodeTypeShort, "short")
+	data = binary.LittleEndian.AppendUint16(data, 0xfffe)
+	data = append(data, byte(NodeTypeEnd))
+
+	r := bytes.NewReader(append(data, 0xff))
+	f, err := ReadBedrockNetworkFromStream(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Len() != 1 {
+		t.Errorf("%d bytes left after the data, want 1", r.Len())
+	}
+	want := &CompoundNode{Values: map[string]Node{
+		"a":     &IntNode{Value: -1},
+		"b":     &IntNode{Value: 10000},
+		"c":     &IntNode{Value: math.MinInt32},
+		"d":     &IntNode{Value: math.MaxInt32},
+		"long":  &LongNode{Value: math.MinInt64},
+		"str":   &StringNode{Value: longStr},
+		"ints":  &IntArrayNode{Values: []Node{&IntNode{Value: -3}, &IntNode{Value: 300}}},
+		"short": &ShortNode{Value: -2},
+	}}
+	if !Equal(f.Root, want) {
+		t.Errorf("read %s, want %s", ToSNBT(f.Root), ToSNBT(want))
+	}
+}
+
+func TestReadBedrockNetworkVarintTooLong(t *testing.T) {
+	data := appendNetworkName(nil, NodeTypeCompound, "")
+	data = appendNetworkName(data, NodeTypeInt, "a")
+	data = append(data, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01, byte(NodeTypeEnd))
+	if _, err := ReadBedrockNetworkFromStream(bytes.NewReader(data)); err == nil || !strings.Contains(err.Error(), "varint exceeds 32 bits") {
+		t.Errorf("read varint of 6 bytes: %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+package nbt
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// pathSegment is a single step of a path like "Data.Player.Inventory[0]".
+type pathSegment struct {
+	Key      string
+	Index    int
+	IsIndex  bool
+	Wildcard bool
+}
+
+// parsePath splits a path into dot-separated compound keys and bracketed list
+// indices. A key "*" or an index "[*]" matches all children.
+func parsePath(path string) ([]pathSegment, error) {
+	if len(path) == 0 {
+		return nil, nil
+	}
+
+	segments := make([]pathSegment, 0)
+	for i, part := range strings.Split(path, ".") {
+		key := part
+		if pos := strings.IndexByte(part, '['); pos >= 0 {
+			key = part[:pos]
+			part = part[pos:]
+		} else {
+			part = ""
+		}
+		if len(key) == 0 && (i > 0 || len(part) == 0) {
+			return nil, fmt.Errorf("empty key in path %q", path)
+		}
+		if len(key) > 0 {
+			if strings.ContainsRune(key, ']') {
+				return nil, fmt.Errorf("unexpected ']' in path %q", path)
+			}
+			segments = append(segments, pathSegment{Key: key, Wildcard: key == "*"})
+		}
+
+		for len(part) > 0 {
+			end := strings.IndexByte(part, ']')
+			if part[0] != '[' || end < 0 {
+				return nil, fmt.Errorf("malformed index in path %q", path)
+			}
+			indexStr := part[1:end]
+			part = part[end+1:]
+
+			if indexStr == "*" {
+				segments = append(segments, pathSegment{IsIndex: true, Wildcard: true})
+				continue
+			}
+			index, err := strconv.Atoi(indexStr)
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid index %q in path %q", indexStr, path)
+			}
+			segments = append(segments, pathSegment{IsIndex: true, Index: index})
+		}
+	}
+	return segments, nil
+}
+
+// match returns all children of node addressed by the segment.
+func (s pathSegment) match(node Node) []Node {
+	switch n := node.(type) {
+	case *CompoundNode:
+		if s.IsIndex {
+			return nil
+		}
+		if s.Wildcard {
+			keys := n.SortedKeys()
+			children := make([]Node, 0, len(keys))
+			for _, key := range keys {
+				children = append(children, n.Values[key])
+			}
+			return children
+		}
+		if child, ok := n.Values[s.Key]; ok {
+			return []Node{child}
+		}
+
+	case *ListNode:
+		return s.matchIndex(n.Values)
+	case *IntArrayNode:
+		return s.matchIndex(n.Values)
+	}
+	return nil
+}
+
+func (s pathSegment) matchIndex(values []Node) []Node {
+	if !s.IsIndex {
+		return nil
+	}
+	if s.Wildcard {
+		return slices.Clone(values)
+	}
+	if s.Index < len(values) {
+		return []Node{values[s.Index]}
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package nbt
+
+import (
+	"fmt"
+	"math"
+)
+
+// Selection holds all nodes matched by a query. Operations on a selection
+// apply to every matched node and can be chained; the first failing operation
+// is recorded and turns all following operations into no-ops.
+type Selection struct {
+	nodes []Node
+	err   error
+}
+
+// Query returns all nodes below the top-level compound matching the path.
+// Paths consist of dot-separated compound keys and bracketed list indices,
+// where "*" and "[*]" match all children, e.g. "Data.Player.Inventory[*]".
+// Segments that do not exist simply match nothing.
+func (f *File) Query(path string) *Selection {
+	rootNode, ok := f.rootCompound()
+	if !ok {
+		return &Selection{err: fmt.Errorf("query %q: missing top-level compound", path)}
+	}
+	return Query(rootNode, path)
+}
+
+func Query(root Node, path string) *Selection {
+	segments, err := parsePath(path)
+	if err != nil {
+		return &Selection{err: fmt.Errorf("query %q: %w", path, err)}
+	}
+
+	nodes := []Node{root}
+	for _, segment := range segments {
+		matches := make([]Node, 0, len(nodes))
+		for _, node := range nodes {
+			matches = append(matches, segment.match(node)...)
+		}
+		nodes = matches
+	}
+	return &Selection{nodes: nodes}
+}
+
+// GetPath returns the single node below the top-level compound addressed by
+// a path like "Data.Player.Pos[1]". Unlike Query, wildcards are not allowed
+// and a missing segment is an error naming the path up to that segment.
+func (f *File) GetPath(path string) (Node, error) {
+	rootNode, ok := f.rootCompound()
+	if !ok {
+		return nil, fmt.Errorf("get %q: missing top-level compound", path)
+	}
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, fmt.Errorf("get %q: %w", path, err)
+	}
+
+	var node Node = rootNode
+	location := "top level"
+	current := ""
+	for _, segment := range segments {
+		if segment.Wildcard {
+			return nil, fmt.Errorf("get %q: wildcards are not allowed", path)
+		}
+
+		if segment.IsIndex {
+			var values []Node
+			switch n := node.(type) {
+			case *ListNode:
+				values = n.Values
+			case *IntArrayNode:
+				values = n.Values
+			default:
+				return nil, fmt.Errorf("get %q: expected list at %s, got %v", path, location, node.Type())
+			}
+			if segment.Index >= len(values) {
+				return nil, fmt.Errorf("get %q: index %d out of range at %s with length %d", path, segment.Index, location, len(values))
+			}
+			node = values[segment.Index]
+			current = indexPath(current, segment.Index)
+		} else {
+			compound, ok := node.(*CompoundNode)
+			if !ok {
+				return nil, fmt.Errorf("get %q: expected compound at %s, got %v", path, location, node.Type())
+			}
+			child, ok := compound.Values[segment.Key]
+			if !ok {
+				return nil, fmt.Errorf("get %q: key %q not found at %s", path, segment.Key, location)
+			}
+			node = child
+			current = childPath(current, segment.Key)
+		}
+		location = current
+	}
+	return node, nil
+}
+
+func (s *Selection) Nodes() []Node { return s.nodes }
+
+func (s *Selection) Len() int { return len(s.nodes) }
+
+func (s *Selection) Err() error { return s.err }
+
+// Set assigns value to key in every matched node. Go values are converted into
+// a separate node per match, and a Node value is cloned for every match, so
+// editing one match later never affects the others. Set only applies to
+// compounds: if any match is not a compound, nothing is modified and the
+// selection records an error.
+func (s *Selection) Set(key string, value interface{}) *Selection {
+	if s.err != nil {
+		return s
+	}
+
+	for _, node := range s.nodes {
+		if _, ok := node.(*CompoundNode); !ok {
+			s.err = fmt.Errorf("set %q: matched %T is not a compound", key, node)
+			return s
+		}
+	}
+	if _, err := nodeFromValue(value); err != nil {
+		s.err = fmt.Errorf("set %q: %w", key, err)
+		return s
+	}
+
+	for _, node := range s.nodes {
+		childNode, _ := nodeFromValue(value)
+		if _, ok := value.(Node); ok {
+			childNode = Clone(childNode)
+		}
+		node.(*CompoundNode).Set(key, childNode)
+	}
+	return s
+}
+
+// nodeFromValue converts a Go value into a newly allocated node. Nodes are
+// returned unchanged.
+func nodeFromValue(value interface{}) (Node, error) {
+	switch v := value.(type) {
+	case Node:
+		return v, nil
+	case bool:
+		if v {
+			return &ByteNode{Value: 1}, nil
+		}
+		return &ByteNode{Value: 0}, nil
+	case byte:
+		return &ByteNode{Value: v}, nil
+	case int8:
+		return &ByteNode{Value: byte(v)}, nil
+	case int16:
+		return &ShortNode{Value: v}, nil
+	case int32:
+		return &IntNode{Value: v}, nil
+	case int:
+		if v < math.MinInt32 || v > math.MaxInt32 {
+			return nil, fmt.Errorf("int value %d exceeds int32 range", v)
+		}
+		return &IntNode{Value: int32(v)}, nil
+	case int64:
+		return &LongNode{Value: v}, nil
+	case float32:
+		return &FloatNode{Value: v}, nil
+	case float64:
+		return &DoubleNode{Value: v}, nil
+	case string:
+		return &StringNode{Value: v}, nil
+	case []int32:
+		node := &IntArrayNode{Values: make([]Node, 0, len(v))}
+		for _, val := range v {
+			node.Values = append(node.Values, &IntNode{Value: val})
+		}
+		return node, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported value type %T", value)
+	}
+}
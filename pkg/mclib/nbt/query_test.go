@@ -0,0 +1,103 @@
+package nbt
+
+import (
+	"strings"
+	"testing"
+)
+
+func testInventoryFile(t *testing.T) *File {
+	t.Helper()
+	f, err := ParseSNBT(`{Data:{Player:{Inventory:[{Slot:0b,id:"minecraft:stone",Count:64b},{Slot:1b,id:"minecraft:dirt",Count:3b,tag:{display:{Name:"x"}}}],Pos:[1.0d,64.0d,2.0d]}}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestQueryWildcardSet(t *testing.T) {
+	f := testInventoryFile(t)
+	sel := f.Query("Data.Player.Inventory[*]").Set("Count", int8(1))
+	if err := sel.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if sel.Len() != 2 {
+		t.Fatalf("matched %d items, want 2", sel.Len())
+	}
+	for i, node := range f.Query("Data.Player.Inventory[*].Count").Nodes() {
+		if count, ok := Byte(node); !ok || count != 1 {
+			t.Errorf("item %d: Count = %v, want 1b", i, node)
+		}
+	}
+}
+
+func TestQuerySetClonesNodes(t *testing.T) {
+	f := testInventoryFile(t)
+	tag := &CompoundNode{Values: map[string]Node{"Damage": &IntNode{Value: 0}}}
+	if err := f.Query("Data.Player.Inventory[*]").Set("tag", tag).Err(); err != nil {
+		t.Fatal(err)
+	}
+	tags := f.Query("Data.Player.Inventory[*].tag").Nodes()
+	if len(tags) != 2 || tags[0] == tags[1] || tags[0] == Node(tag) {
+		t.Fatalf("matches share the set node")
+	}
+	tags[0].(*CompoundNode).Values["Damage"].(*IntNode).Value = 5
+	if damage, _ := tags[1].(*CompoundNode).Int("Damage"); damage != 0 {
+		t.Errorf("editing one match changed another")
+	}
+}
+
+func TestQuerySetRejectsNonCompound(t *testing.T) {
+	f := testInventoryFile(t)
+	if err := f.Query("Data.Player.Pos[*]").Set("x", 1).Err(); err == nil {
+		t.Errorf("expected error setting a key on doubles")
+	}
+	if err := f.Query("Data.Player.Inventory[*]").Set("x", struct{}{}).Err(); err == nil {
+		t.Errorf("expected error for unsupported value type")
+	}
+}
+
+func TestQueryMissingSegment(t *testing.T) {
+	f := testInventoryFile(t)
+	if n := f.Query("Data.Missing[*]").Len(); n != 0 {
+		t.Errorf("missing path matched %d nodes", n)
+	}
+	if n := f.Query("Data.Player.Inventory[5]").Len(); n != 0 {
+		t.Errorf("out-of-range index matched %d nodes", n)
+	}
+}
+
+func TestGetPath(t *testing.T) {
+	f := testInventoryFile(t)
+
+	node, err := f.GetPath("Data.Player.Inventory[1].tag.display.Name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name, ok := Str(node); !ok || name != "x" {
+		t.Errorf("Name = %v, want \"x\"", node)
+	}
+	node, err = f.GetPath("Data.Player.Pos[1]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if y, ok := F64(node); !ok || y != 64 {
+		t.Errorf("Pos[1] = %v, want 64.0d", node)
+	}
+
+	tests := []struct {
+		path string
+		err  string
+	}{
+		{"Data.Player.Missing", `key "Missing" not found at Data.Player`},
+		{"Data.Player.Pos[3]", "index 3 out of range at Data.Player.Pos with length 3"},
+		{"Data.Player.Inventory[0].id[0]", "expected list at Data.Player.Inventory[0].id"},
+		{"Data.Player.Pos[0].x", "expected compound at Data.Player.Pos[0]"},
+		{"Data.Player.Inventory[*]", "wildcards are not allowed"},
+	}
+	for _, test := range tests {
+		_, err := f.GetPath(test.path)
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("GetPath(%q) = %v, want error containing %q", test.path, err, test.err)
+		}
+	}
+}
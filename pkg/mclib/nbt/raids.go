@@ -0,0 +1,52 @@
+package nbt
+
+import "fmt"
+
+type Raid struct {
+	ID            int32
+	Center        [3]int32
+	Status        string
+	Started       bool
+	Active        bool
+	BadOmenLevel  int32
+	GroupsSpawned int32
+	NumGroups     int32
+	TicksActive   int64
+}
+
+// ParseRaids extracts all raids from a raids.dat file. Files without any
+// raid data yield an empty slice.
+func ParseRaids(f *File) ([]Raid, error) {
+	sel := f.Query("data.Raids[*]")
+	if err := sel.Err(); err != nil {
+		return nil, err
+	}
+
+	raids := make([]Raid, 0, sel.Len())
+	for i, node := range sel.Nodes() {
+		raidNode, ok := node.(*CompoundNode)
+		if !ok {
+			return nil, fmt.Errorf("raid %d: expected compound, got %T", i, node)
+		}
+		raids = append(raids, parseRaid(raidNode))
+	}
+	return raids, nil
+}
+
+func parseRaid(n *CompoundNode) Raid {
+	raid := Raid{}
+	raid.ID, _ = Int(n.Values["Id"])
+	raid.Center[0], _ = Int(n.Values["CX"])
+	raid.Center[1], _ = Int(n.Values["CY"])
+	raid.Center[2], _ = Int(n.Values["CZ"])
+	raid.Status, _ = Str(n.Values["Status"])
+	started, _ := Byte(n.Values["Started"])
+	raid.Started = started != 0
+	active, _ := Byte(n.Values["Active"])
+	raid.Active = active != 0
+	raid.BadOmenLevel, _ = Int(n.Values["BadOmenLevel"])
+	raid.GroupsSpawned, _ = Int(n.Values["GroupsSpawned"])
+	raid.NumGroups, _ = Int(n.Values["NumGroups"])
+	raid.TicksActive, _ = Long(n.Values["TicksActive"])
+	return raid
+}
@@ -0,0 +1,49 @@
+package nbt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRaids(t *testing.T) {
+	f, err := ParseSNBT(`{DataVersion:3465,data:{NextAvailableID:3,Tick:120000,Raids:[` +
+		`{Id:1,CX:100,CY:64,CZ:-200,Status:"ongoing",Started:1b,Active:1b,BadOmenLevel:2,GroupsSpawned:1,NumGroups:5,TicksActive:1200L,HeroesOfTheVillage:[]},` +
+		`{Id:2,CX:-5,CY:70,CZ:8,Status:"victory",Started:1b,Active:0b,BadOmenLevel:1,GroupsSpawned:3,NumGroups:3,TicksActive:48000L}]}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raids, err := ParseRaids(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Raid{
+		{ID: 1, Center: [3]int32{100, 64, -200}, Status: "ongoing", Started: true, Active: true, BadOmenLevel: 2, GroupsSpawned: 1, NumGroups: 5, TicksActive: 1200},
+		{ID: 2, Center: [3]int32{-5, 70, 8}, Status: "victory", Started: true, BadOmenLevel: 1, GroupsSpawned: 3, NumGroups: 3, TicksActive: 48000},
+	}
+	if !reflect.DeepEqual(raids, want) {
+		t.Errorf("ParseRaids() = %+v\nwant %+v", raids, want)
+	}
+}
+
+func TestParseRaidsEmpty(t *testing.T) {
+	for _, snbt := range []string{`{}`, `{data:{}}`, `{data:{Raids:[]}}`} {
+		f, err := ParseSNBT(snbt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		raids, err := ParseRaids(f)
+		if err != nil {
+			t.Errorf("%s: %v", snbt, err)
+		} else if raids == nil || len(raids) != 0 {
+			t.Errorf("%s: ParseRaids() = %#v, want empty slice", snbt, raids)
+		}
+	}
+
+	f, err := ParseSNBT(`{data:{Raids:[1,2]}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseRaids(f); err == nil {
+		t.Errorf("expected error for raids that are not compounds")
+	}
+}
@@ -0,0 +1,27 @@
+package nbt
+
+import (
+	"bufio"
+	"context"
+	"io"
+)
+
+// Reader reads NBT data from a stream using options that are set once. The
+// input is buffered by the Reader itself, so uncompressed files stored back to
+// back are read by calling Read repeatedly, which fails with ErrEmptyInput at
+// the end of the input. A Reader must not be used concurrently, but the files
+// it returns do not share any state with it or with each other.
+type Reader struct {
+	br   *bufio.Reader
+	opts ReadOptions
+}
+
+func NewReader(r io.Reader, opts ReadOptions) *Reader {
+	return &Reader{br: bufio.NewReader(r), opts: opts}
+}
+
+// Read reads the next file like ReadFromStreamWithOptions, detecting its
+// compression first.
+func (r *Reader) Read() (*File, error) {
+	return readFromStream(context.Background(), r.br, r.opts)
+}
@@ -0,0 +1,42 @@
+package nbt
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestReader(t *testing.T) {
+	first := testFile(t)
+	second, err := ParseSNBT(`{Name:"second",Values:[L;1L,-1L]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	for _, f := range []*File{first, second} {
+		if err := WriteToStreamWithOptions(&buf, f, WriteOptions{Endianness: LittleEndian}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r := NewReader(&buf, ReadOptions{Endianness: LittleEndian, MaxDepth: 3})
+	for _, want := range []*File{first, second} {
+		f, err := r.Read()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !Equal(f.Root, want.Root) {
+			t.Errorf("read %s, want %s", ToSNBT(f.Root), ToSNBT(want.Root))
+		}
+	}
+	if _, err := r.Read(); !errors.Is(err, ErrEmptyInput) {
+		t.Errorf("read at end of input: %v, want %v", err, ErrEmptyInput)
+	}
+
+	// the options apply to every file read
+	deep := encodeFile(t, testFile(t))
+	r = NewReader(bytes.NewReader(deep), ReadOptions{MaxDepth: 1})
+	if _, err := r.Read(); !errors.Is(err, ErrDepthExceeded) {
+		t.Errorf("read with MaxDepth 1: %v, want %v", err, ErrDepthExceeded)
+	}
+}
@@ -0,0 +1,39 @@
+package nbt
+
+// Schema returns the type of every node in the file by its path. List
+// elements are merged under the index wildcard, e.g. "Inventory[*].id", so
+// "Inventory[*]" holds the element type of the list. The paths can be passed
+// to Query.
+func Schema(f *File) map[string]NodeType {
+	schema := make(map[string]NodeType)
+	rootNode, ok := f.rootCompound()
+	if !ok {
+		return schema
+	}
+	for key, childNode := range rootNode.Values {
+		collectSchema(schema, key, childNode)
+	}
+	return schema
+}
+
+func collectSchema(schema map[string]NodeType, path string, node Node) {
+	if node == nil {
+		return
+	}
+	schema[path] = node.Type()
+
+	switch n := node.(type) {
+	case *CompoundNode:
+		for key, childNode := range n.Values {
+			collectSchema(schema, childPath(path, key), childNode)
+		}
+	case *ListNode:
+		elemPath := path + "[*]"
+		for _, childNode := range n.Values {
+			collectSchema(schema, elemPath, childNode)
+		}
+		if _, ok := schema[elemPath]; !ok && len(n.Values) == 0 {
+			schema[elemPath] = n.ElementType
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package nbt
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestSchema(t *testing.T) {
+	f, err := ParseSNBT(`{Data:{LevelName:"x",Pos:[1.0d,2.0d],Inventory:[{id:"a",Count:1b},{id:"b",Count:2b,tag:{Damage:1}}],Tags:[],Seeds:[L;1L]}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Root.(*CompoundNode).Set("Typed", &ListNode{ElementType: NodeTypeInt})
+
+	want := map[string]NodeType{
+		"Data":                         NodeTypeCompound,
+		"Data.LevelName":               NodeTypeString,
+		"Data.Pos":                     NodeTypeList,
+		"Data.Pos[*]":                  NodeTypeDouble,
+		"Data.Inventory":               NodeTypeList,
+		"Data.Inventory[*]":            NodeTypeCompound,
+		"Data.Inventory[*].id":         NodeTypeString,
+		"Data.Inventory[*].Count":      NodeTypeByte,
+		"Data.Inventory[*].tag":        NodeTypeCompound,
+		"Data.Inventory[*].tag.Damage": NodeTypeInt,
+		"Data.Tags":                    NodeTypeList,
+		"Data.Tags[*]":                 NodeTypeEnd,
+		"Data.Seeds":                   NodeTypeLongArray,
+		"Typed":                        NodeTypeList,
+		"Typed[*]":                     NodeTypeInt,
+	}
+	if schema := Schema(f); !maps.Equal(schema, want) {
+		t.Errorf("Schema() = %v\nwant %v", schema, want)
+	}
+
+	// the paths address the nodes they describe
+	if nodes := f.Query("Data.Inventory[*].tag.Damage").Nodes(); len(nodes) != 1 {
+		t.Errorf("schema path matches %d nodes", len(nodes))
+	}
+}
@@ -0,0 +1,69 @@
+package nbt
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// selfTestNodes returns a sample node with non-zero contents for every type
+// except the end tag.
+func selfTestNodes() []Node {
+	return []Node{
+		&ByteNode{Value: 0x80},
+		&ShortNode{Value: -2},
+		&IntNode{Value: -3},
+		&LongNode{Value: -4},
+		&FloatNode{Value: 1.5},
+		&DoubleNode{Value: -2.5},
+		&ByteArrayNode{Values: []byte{1, 0xff}},
+		&StringNode{Value: "a\x00😀"},
+		&ListNode{ElementType: NodeTypeShort, Values: []Node{&ShortNode{Value: 1}, &ShortNode{Value: 2}}},
+		&CompoundNode{Values: map[string]Node{"key": &StringNode{Value: "value"}}},
+		&IntArrayNode{Values: []Node{&IntNode{Value: 1}, &IntNode{Value: -1}}},
+		&LongArrayNode{Values: []int64{1, -1}},
+	}
+}
+
+// SelfTest verifies that every node type is written and read back as itself
+// with unchanged contents, passing through the actual encoder and decoder. It
+// can be called from tests of consuming code as a sanity check after
+// upgrading this package.
+func SelfTest() error {
+	for nodeType := NodeTypeEnd; nodeType <= NodeTypeLongArray; nodeType++ {
+		if !IsValidNodeType(nodeType) {
+			return fmt.Errorf("node type %v is not valid", nodeType)
+		}
+	}
+	if IsValidNodeType(NodeTypeLongArray + 1) {
+		return fmt.Errorf("node type %v is valid", NodeTypeLongArray+1)
+	}
+
+	nodes := selfTestNodes()
+	if len(nodes) != int(NodeTypeLongArray) {
+		return fmt.Errorf("%d sample nodes for %d node types", len(nodes), NodeTypeLongArray)
+	}
+	for i, node := range nodes {
+		nodeType := NodeType(i + 1)
+		if node.Type() != nodeType {
+			return fmt.Errorf("%T reports type %v instead of %v", node, node.Type(), nodeType)
+		}
+
+		var buf bytes.Buffer
+		f := &File{Root: &CompoundNode{Values: map[string]Node{"value": node}}}
+		if err := WriteToStream(&buf, f); err != nil {
+			return fmt.Errorf("write %v: %w", nodeType, err)
+		}
+		readFile, err := ReadFromBytes(buf.Bytes())
+		if err != nil {
+			return fmt.Errorf("read %v: %w", nodeType, err)
+		}
+		readNode := readFile.Root.(*CompoundNode).Values["value"]
+		if readNode == nil || readNode.Type() != nodeType {
+			return fmt.Errorf("%v is read back as %T", nodeType, readNode)
+		}
+		if !Equal(node, readNode) {
+			return fmt.Errorf("%v changes when written and read back: %v", nodeType, readNode)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,550 @@
+package nbt
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type SNBTOptions struct {
+	// Indent enables multi-line output with the given indentation per level.
+	Indent string
+	// MinimizeEscapes quotes strings with single quotes if that requires less
+	// escaping than double quotes.
+	MinimizeEscapes bool
+}
+
+// ToSNBT renders a node in the compact text format used by Minecraft
+// commands. Compound keys are sorted, so the output is deterministic.
+func ToSNBT(n Node) string {
+	return ToSNBTWithOptions(n, SNBTOptions{})
+}
+
+// ToSNBTWithOptions renders a node as SNBT. An UnknownNode has no text
+// representation and is rendered as a byte array of its raw data.
+func ToSNBTWithOptions(n Node, opts SNBTOptions) string {
+	w := &snbtWriter{opts: opts}
+	w.writeNode(n, 0)
+	return w.sb.String()
+}
+
+// Decode reads NBT data that is either uncompressed or compressed with gzip or
+// zlib and renders it as indented SNBT with sorted keys, so the output of equal
+// data is identical and can be diffed. The name of the root compound is not
+// part of the output.
+func Decode(input []byte) (string, error) {
+	f, err := ReadFromStream(bytes.NewReader(input))
+	if err != nil {
+		return "", err
+	}
+	return ToSNBTWithOptions(f.Root, SNBTOptions{Indent: "  "}), nil
+}
+
+// Encode parses SNBT and returns its binary representation with the given
+// compression, so text produced by Decode can be edited and saved again. The
+// root compound is written without name.
+func Encode(snbt string, compression CompressionType) ([]byte, error) {
+	f, err := ParseSNBT(snbt)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := writeCompressed(&buf, f, compression); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+type snbtWriter struct {
+	sb   strings.Builder
+	opts SNBTOptions
+}
+
+func (w *snbtWriter) newline(depth int) {
+	if len(w.opts.Indent) > 0 {
+		w.sb.WriteByte('\n')
+		w.sb.WriteString(strings.Repeat(w.opts.Indent, depth))
+	}
+}
+
+func (w *snbtWriter) writeNode(node Node, depth int) {
+	switch n := node.(type) {
+	case *ByteNode:
+		w.sb.WriteString(strconv.Itoa(int(int8(n.Value))) + "b")
+	case *ShortNode:
+		w.sb.WriteString(strconv.Itoa(int(n.Value)) + "s")
+	case *IntNode:
+		w.sb.WriteString(strconv.Itoa(int(n.Value)))
+	case *LongNode:
+		w.sb.WriteString(strconv.FormatInt(n.Value, 10) + "L")
+	case *FloatNode:
+		w.sb.WriteString(strconv.FormatFloat(float64(n.Value), 'g', -1, 32) + "f")
+	case *DoubleNode:
+		w.sb.WriteString(strconv.FormatFloat(n.Value, 'g', -1, 64) + "d")
+	case *ByteArrayNode:
+		w.writeByteArray(n.Values)
+	case *StringNode:
+		w.writeString(n.Value)
+	case *ListNode:
+		w.writeList(n.Values, depth)
+	case *CompoundNode:
+		w.writeCompound(n, depth)
+	case *IntArrayNode:
+		w.sb.WriteString("[I;")
+		for i, childNode := range n.Values {
+			if i > 0 {
+				w.sb.WriteByte(',')
+			}
+			w.writeNode(childNode, depth+1)
+		}
+		w.sb.WriteByte(']')
+	case *LongArrayNode:
+		w.sb.WriteString("[L;")
+		for i, val := range n.Values {
+			if i > 0 {
+				w.sb.WriteByte(',')
+			}
+			w.sb.WriteString(strconv.FormatInt(val, 10) + "L")
+		}
+		w.sb.WriteByte(']')
+	case *UnknownNode:
+		w.writeByteArray(n.Raw)
+
+	default:
+		w.sb.WriteString("null")
+	}
+}
+
+func (w *snbtWriter) writeByteArray(values []byte) {
+	w.sb.WriteString("[B;")
+	for i, val := range values {
+		if i > 0 {
+			w.sb.WriteByte(',')
+		}
+		w.sb.WriteString(strconv.Itoa(int(int8(val))) + "b")
+	}
+	w.sb.WriteByte(']')
+}
+
+func (w *snbtWriter) writeList(values []Node, depth int) {
+	w.sb.WriteByte('[')
+	for i, childNode := range values {
+		if i > 0 {
+			w.sb.WriteByte(',')
+		}
+		w.newline(depth + 1)
+		w.writeNode(childNode, depth+1)
+	}
+	if len(values) > 0 {
+		w.newline(depth)
+	}
+	w.sb.WriteByte(']')
+}
+
+func (w *snbtWriter) writeCompound(n *CompoundNode, depth int) {
+	w.sb.WriteByte('{')
+	keys := n.SortedKeys()
+	for i, key := range keys {
+		if i > 0 {
+			w.sb.WriteByte(',')
+		}
+		w.newline(depth + 1)
+		if isUnquotedSNBT(key) {
+			w.sb.WriteString(key)
+		} else {
+			w.writeString(key)
+		}
+		w.sb.WriteByte(':')
+		if len(w.opts.Indent) > 0 {
+			w.sb.WriteByte(' ')
+		}
+		w.writeNode(n.Values[key], depth+1)
+	}
+	if len(keys) > 0 {
+		w.newline(depth)
+	}
+	w.sb.WriteByte('}')
+}
+
+func (w *snbtWriter) writeString(val string) {
+	quote := byte('"')
+	if w.opts.MinimizeEscapes && strings.Count(val, `'`) < strings.Count(val, `"`) {
+		quote = '\''
+	}
+
+	w.sb.WriteByte(quote)
+	for i := 0; i < len(val); i++ {
+		if val[i] == quote || val[i] == '\\' {
+			w.sb.WriteByte('\\')
+		}
+		w.sb.WriteByte(val[i])
+	}
+	w.sb.WriteByte(quote)
+}
+
+func isUnquotedSNBTChar(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') ||
+		c == '_' || c == '-' || c == '.' || c == '+'
+}
+
+func isUnquotedSNBT(s string) bool {
+	if len(s) == 0 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isUnquotedSNBTChar(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// SNBTSyntaxError reports malformed SNBT input along with the byte offset at
+// which the problem was detected.
+type SNBTSyntaxError struct {
+	Offset  int
+	Message string
+}
+
+func (e *SNBTSyntaxError) Error() string {
+	return fmt.Sprintf("snbt syntax error at offset %d: %s", e.Offset, e.Message)
+}
+
+// ParseSNBT parses a compound in the text format used by Minecraft commands.
+// Strings may be quoted with double or single quotes, where a backslash
+// escapes the quote character and itself. Lists take the type of their
+// elements. SNBT has no notation for the element type of an empty list, so
+// like in the game, empty lists are parsed as lists of TAG_End.
+func ParseSNBT(s string) (*File, error) {
+	p := &snbtParser{s: s}
+	p.skipWhitespace()
+	start := p.pos
+	node, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	rootNode, ok := node.(*CompoundNode)
+	if !ok {
+		return nil, &SNBTSyntaxError{Offset: start, Message: "root must be a compound"}
+	}
+	p.skipWhitespace()
+	if p.pos < len(p.s) {
+		return nil, p.errorf("unexpected trailing data")
+	}
+
+	return &File{
+		Root: rootNode,
+	}, nil
+}
+
+type snbtParser struct {
+	s   string
+	pos int
+}
+
+func (p *snbtParser) errorf(format string, args ...interface{}) error {
+	return &SNBTSyntaxError{Offset: p.pos, Message: fmt.Sprintf(format, args...)}
+}
+
+func (p *snbtParser) skipWhitespace() {
+	for p.pos < len(p.s) && strings.IndexByte(" \t\r\n", p.s[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+func (p *snbtParser) peek() (byte, bool) {
+	if p.pos >= len(p.s) {
+		return 0, false
+	}
+	return p.s[p.pos], true
+}
+
+func (p *snbtParser) expect(c byte) error {
+	p.skipWhitespace()
+	if next, ok := p.peek(); !ok || next != c {
+		if !ok {
+			return p.errorf("expected %q, got end of input", c)
+		}
+		return p.errorf("expected %q, got %q", c, next)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *snbtParser) parseValue() (Node, error) {
+	p.skipWhitespace()
+	c, ok := p.peek()
+	if !ok {
+		return nil, p.errorf("expected value, got end of input")
+	}
+
+	switch {
+	case c == '{':
+		return p.parseCompound()
+	case c == '[':
+		if p.pos+2 < len(p.s) && p.s[p.pos+2] == ';' && strings.IndexByte("BIL", p.s[p.pos+1]) >= 0 {
+			return p.parseArray()
+		}
+		return p.parseList()
+	case c == '"' || c == '\'':
+		val, err := p.parseQuotedString()
+		if err != nil {
+			return nil, err
+		}
+		return &StringNode{Value: val}, nil
+	case isUnquotedSNBTChar(c):
+		return parseUnquotedSNBT(p.parseUnquoted()), nil
+
+	default:
+		return nil, p.errorf("unexpected character %q", c)
+	}
+}
+
+func (p *snbtParser) parseCompound() (*CompoundNode, error) {
+	p.pos++
+	node := &CompoundNode{Values: make(map[string]Node)}
+
+	p.skipWhitespace()
+	if c, ok := p.peek(); ok && c == '}' {
+		p.pos++
+		return node, nil
+	}
+
+	for {
+		key, err := p.parseKey()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(':'); err != nil {
+			return nil, err
+		}
+		val, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		node.Set(key, val)
+
+		p.skipWhitespace()
+		c, ok := p.peek()
+		if !ok {
+			return nil, p.errorf("expected ',' or '}', got end of input")
+		}
+		p.pos++
+		if c == '}' {
+			return node, nil
+		}
+		if c != ',' {
+			p.pos--
+			return nil, p.errorf("expected ',' or '}', got %q", c)
+		}
+	}
+}
+
+func (p *snbtParser) parseKey() (string, error) {
+	p.skipWhitespace()
+	c, ok := p.peek()
+	if !ok {
+		return "", p.errorf("expected key, got end of input")
+	}
+	if c == '"' || c == '\'' {
+		return p.parseQuotedString()
+	}
+	if !isUnquotedSNBTChar(c) {
+		return "", p.errorf("expected key, got %q", c)
+	}
+	return p.parseUnquoted(), nil
+}
+
+func (p *snbtParser) parseList() (*ListNode, error) {
+	p.pos++
+	node := &ListNode{Values: make([]Node, 0)}
+
+	p.skipWhitespace()
+	if c, ok := p.peek(); ok && c == ']' {
+		p.pos++
+		return node, nil
+	}
+
+	for {
+		start := p.pos
+		val, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		if len(node.Values) > 0 && val.Type() != node.Values[0].Type() {
+			return nil, &SNBTSyntaxError{Offset: start, Message: fmt.Sprintf("list element of type %v differs from list type %v", val.Type(), node.Values[0].Type())}
+		}
+		node.ElementType = val.Type()
+		node.Values = append(node.Values, val)
+
+		p.skipWhitespace()
+		c, ok := p.peek()
+		if !ok {
+			return nil, p.errorf("expected ',' or ']', got end of input")
+		}
+		p.pos++
+		if c == ']' {
+			return node, nil
+		}
+		if c != ',' {
+			p.pos--
+			return nil, p.errorf("expected ',' or ']', got %q", c)
+		}
+	}
+}
+
+func (p *snbtParser) parseArray() (Node, error) {
+	arrayType := p.s[p.pos+1]
+	p.pos += 3
+
+	values := make([]Node, 0)
+	p.skipWhitespace()
+	if c, ok := p.peek(); ok && c == ']' {
+		p.pos++
+	} else {
+		for {
+			p.skipWhitespace()
+			start := p.pos
+			val, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			if (arrayType == 'B' && val.Type() != NodeTypeByte) ||
+				(arrayType == 'I' && val.Type() != NodeTypeInt) ||
+				(arrayType == 'L' && val.Type() != NodeTypeLong) {
+				return nil, &SNBTSyntaxError{Offset: start, Message: fmt.Sprintf("invalid element of type %v in %c array", val.Type(), arrayType)}
+			}
+			values = append(values, val)
+
+			p.skipWhitespace()
+			c, ok := p.peek()
+			if !ok {
+				return nil, p.errorf("expected ',' or ']', got end of input")
+			}
+			p.pos++
+			if c == ']' {
+				break
+			}
+			if c != ',' {
+				p.pos--
+				return nil, p.errorf("expected ',' or ']', got %q", c)
+			}
+		}
+	}
+
+	switch arrayType {
+	case 'B':
+		node := &ByteArrayNode{Values: make([]byte, 0, len(values))}
+		for _, val := range values {
+			node.Values = append(node.Values, val.(*ByteNode).Value)
+		}
+		return node, nil
+	case 'I':
+		return &IntArrayNode{Values: values}, nil
+	default:
+		node := &LongArrayNode{Values: make([]int64, 0, len(values))}
+		for _, val := range values {
+			node.Values = append(node.Values, val.(*LongNode).Value)
+		}
+		return node, nil
+	}
+}
+
+func (p *snbtParser) parseQuotedString() (string, error) {
+	start := p.pos
+	quote := p.s[p.pos]
+	p.pos++
+
+	var sb strings.Builder
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		p.pos++
+		switch c {
+		case quote:
+			return sb.String(), nil
+		case '\\':
+			if p.pos >= len(p.s) {
+				return "", &SNBTSyntaxError{Offset: start, Message: "unterminated string"}
+			}
+			escaped := p.s[p.pos]
+			if escaped != quote && escaped != '\\' {
+				p.pos--
+				return "", p.errorf("invalid escape sequence \\%c", escaped)
+			}
+			sb.WriteByte(escaped)
+			p.pos++
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return "", &SNBTSyntaxError{Offset: start, Message: "unterminated string"}
+}
+
+func (p *snbtParser) parseUnquoted() string {
+	start := p.pos
+	for p.pos < len(p.s) && isUnquotedSNBTChar(p.s[p.pos]) {
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+var (
+	snbtIntegerPattern = regexp.MustCompile(`^[-+]?[0-9]+[bBsSlL]?$`)
+	snbtFloatPattern   = regexp.MustCompile(`^[-+]?(?:[0-9]+\.?|[0-9]*\.[0-9]+)(?:[eE][-+]?[0-9]+)?[fFdD]$`)
+	// without suffix, a decimal point is required to tell doubles from strings
+	snbtDoublePattern = regexp.MustCompile(`^[-+]?(?:[0-9]+\.|[0-9]*\.[0-9]+)(?:[eE][-+]?[0-9]+)?$`)
+)
+
+// parseUnquotedSNBT infers the node type from an unquoted token. Like in
+// Minecraft, tokens that are no valid number end up as strings.
+func parseUnquotedSNBT(token string) Node {
+	switch token {
+	case "true":
+		return &ByteNode{Value: 1}
+	case "false":
+		return &ByteNode{Value: 0}
+	}
+
+	if snbtIntegerPattern.MatchString(token) {
+		digits, suffix := token, byte(0)
+		if last := token[len(token)-1]; last < '0' || last > '9' {
+			digits, suffix = token[:len(token)-1], last|0x20
+		}
+		switch suffix {
+		case 'b':
+			if val, err := strconv.ParseInt(digits, 10, 8); err == nil {
+				return &ByteNode{Value: byte(int8(val))}
+			}
+		case 's':
+			if val, err := strconv.ParseInt(digits, 10, 16); err == nil {
+				return &ShortNode{Value: int16(val)}
+			}
+		case 'l':
+			if val, err := strconv.ParseInt(digits, 10, 64); err == nil {
+				return &LongNode{Value: val}
+			}
+		default:
+			if val, err := strconv.ParseInt(digits, 10, 32); err == nil {
+				return &IntNode{Value: int32(val)}
+			}
+		}
+	}
+
+	if snbtFloatPattern.MatchString(token) || snbtDoublePattern.MatchString(token) {
+		digits, suffix := token, byte('d')
+		if last := token[len(token)-1] | 0x20; last == 'f' || last == 'd' {
+			digits, suffix = token[:len(token)-1], last
+		}
+		if suffix == 'f' {
+			if val, err := strconv.ParseFloat(digits, 32); err == nil {
+				return &FloatNode{Value: float32(val)}
+			}
+		} else if val, err := strconv.ParseFloat(digits, 64); err == nil {
+			return &DoubleNode{Value: val}
+		}
+	}
+
+	return &StringNode{Value: token}
+}
@@ -0,0 +1,226 @@
+package nbt
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseSNBTSingleQuotes(t *testing.T) {
+	f, err := ParseSNBT(`{'single key':'it\'s "quoted"',double:"it's \"quoted\"",path:'C:\\dir'}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := f.Root.(*CompoundNode)
+	want := map[string]string{
+		"single key": `it's "quoted"`,
+		"double":     `it's "quoted"`,
+		"path":       `C:\dir`,
+	}
+	for key, val := range want {
+		if str, _ := root.Str(key); str != val {
+			t.Errorf("%s = %q, want %q", key, str, val)
+		}
+	}
+
+	for _, snbt := range []string{`{a:'unterminated}`, `{a:'\"'}`} {
+		if _, err := ParseSNBT(snbt); err == nil {
+			t.Errorf("%s: expected syntax error", snbt)
+		}
+	}
+}
+
+func TestToSNBTMinimizeEscapes(t *testing.T) {
+	tests := []struct {
+		value string
+		plain string
+		min   string
+	}{
+		{`say "hi"`, `"say \"hi\""`, `'say "hi"'`},
+		{`it's`, `"it's"`, `"it's"`},
+		{`"it's"`, `"\"it's\""`, `'"it\'s"'`},
+		{`'a' "b"`, `"'a' \"b\""`, `"'a' \"b\""`},
+	}
+	for _, test := range tests {
+		node := &StringNode{Value: test.value}
+		if out := ToSNBT(node); out != test.plain {
+			t.Errorf("ToSNBT(%q) = %s, want %s", test.value, out, test.plain)
+		}
+		out := ToSNBTWithOptions(node, SNBTOptions{MinimizeEscapes: true})
+		if out != test.min {
+			t.Errorf("ToSNBT(%q) with MinimizeEscapes = %s, want %s", test.value, out, test.min)
+		}
+
+		// both forms parse back to the original value
+		f, err := ParseSNBT("{v:" + out + "}")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if val, _ := f.Root.(*CompoundNode).Str("v"); val != test.value {
+			t.Errorf("%s parsed as %q, want %q", out, val, test.value)
+		}
+	}
+}
+
+func TestDecodeCompressions(t *testing.T) {
+	raw := encodeFile(t, testFile(t))
+	want, err := Decode(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(want, "{\n  Data: {\n    LevelName: \"test\",\n    Pos: [") {
+		t.Errorf("output is not indented with sorted keys:\n%s", want)
+	}
+
+	text, err := Decode(compressBytes(t, raw, CompressionGZip))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != want {
+		t.Errorf("gzip output differs:\n%s\nwant\n%s", text, want)
+	}
+
+	// the order of keys in the input does not matter
+	reordered := NewFile("")
+	level := &CompoundNode{}
+	level.Set("Version", &CompoundNode{Values: map[string]Node{"Id": &IntNode{Value: 3465}}})
+	level.Set("Values", &IntArrayNode{Values: []Node{&IntNode{Value: 1}, &IntNode{Value: 2}, &IntNode{Value: 3}}})
+	level.Set("Pos", &ListNode{ElementType: NodeTypeDouble, Values: []Node{&DoubleNode{Value: 1.5}, &DoubleNode{Value: 2.5}}})
+	level.Set("LevelName", &StringNode{Value: "test"})
+	reordered.Root.(*CompoundNode).Set("Data", level)
+	text, err = Decode(compressBytes(t, encodeFile(t, reordered), CompressionGZip))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != want {
+		t.Errorf("output of reordered input differs:\n%s\nwant\n%s", text, want)
+	}
+}
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	// the input is formatted like the output of Decode
+	input := `{
+  Data: {
+    LevelName: "my \"world\"",
+    Pos: [
+      1.5d,
+      -2d
+    ],
+    Seed: -4172144997902289642L,
+    Values: [I;1,-2,3],
+    flags: [B;0b,1b],
+    hardcore: 1b
+  }
+}`
+	for _, compression := range []CompressionType{CompressionNone, CompressionGZip, CompressionZlib} {
+		data, err := Encode(input, compression)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if detected := detectCompression(data); detected != compression {
+			t.Errorf("encoded with %v instead of %v", detected, compression)
+		}
+		text, err := Decode(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if text != input {
+			t.Errorf("%v round trip:\n%s\nwant\n%s", compression, text, input)
+		}
+	}
+
+	if _, err := Encode(`{a:`, CompressionNone); err == nil {
+		t.Errorf("expected error for malformed SNBT")
+	}
+}
+
+func TestParseSNBTPrimitives(t *testing.T) {
+	tests := []struct {
+		snbt string
+		node Node
+	}{
+		{"1b", &ByteNode{Value: 1}},
+		{"-128B", &ByteNode{Value: 0x80}},
+		{"true", &ByteNode{Value: 1}},
+		{"false", &ByteNode{Value: 0}},
+		{"-5s", &ShortNode{Value: -5}},
+		{"42", &IntNode{Value: 42}},
+		{"-4172144997902289642L", &LongNode{Value: -4172144997902289642}},
+		{"0.5f", &FloatNode{Value: 0.5}},
+		{"1.5d", &DoubleNode{Value: 1.5}},
+		{"2.", &DoubleNode{Value: 2}},
+		{"-1e3d", &DoubleNode{Value: -1000}},
+		{`"42"`, &StringNode{Value: "42"}},
+		{"stone_bricks", &StringNode{Value: "stone_bricks"}},
+		// out of range for a byte, so it is a string like in the game
+		{"300b", &StringNode{Value: "300b"}},
+		{"[B;1b,-1b]", &ByteArrayNode{Values: []byte{1, 0xff}}},
+		{"[I;1,-2]", &IntArrayNode{Values: []Node{&IntNode{Value: 1}, &IntNode{Value: -2}}}},
+		{"[L;1L,-2L]", &LongArrayNode{Values: []int64{1, -2}}},
+	}
+	for _, test := range tests {
+		f, err := ParseSNBT("{v:" + test.snbt + "}")
+		if err != nil {
+			t.Errorf("%s: %v", test.snbt, err)
+			continue
+		}
+		if node := f.Root.(*CompoundNode).Values["v"]; !Equal(node, test.node) {
+			t.Errorf("%s parsed as %v %s, want %v %s", test.snbt, node.Type(), ToSNBT(node), test.node.Type(), ToSNBT(test.node))
+		}
+	}
+}
+
+func TestParseSNBTNested(t *testing.T) {
+	f, err := ParseSNBT(` { Data : { Player : { Inventory : [ { id : "minecraft:stone" , Count : 64b } , { id : "minecraft:dirt" } ] , Pos : [ 1.0d , 2.0d ] } , Tags : [ [ a , b ] , [ ] ] } } `)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inventory, err := f.GetPath("Data.Player.Inventory")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list := inventory.(*ListNode); list.ElementType != NodeTypeCompound || len(list.Values) != 2 {
+		t.Errorf("Inventory = %s", ToSNBT(list))
+	}
+	if node, err := f.GetPath("Data.Player.Inventory[1].id"); err != nil || !Equal(node, &StringNode{Value: "minecraft:dirt"}) {
+		t.Errorf("Inventory[1].id = %v, %v", node, err)
+	}
+	tags, err := f.GetPath("Data.Tags")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &ListNode{ElementType: NodeTypeList, Values: []Node{
+		&ListNode{ElementType: NodeTypeString, Values: []Node{&StringNode{Value: "a"}, &StringNode{Value: "b"}}},
+		&ListNode{ElementType: NodeTypeEnd},
+	}}
+	if !Equal(tags, want) {
+		t.Errorf("Tags = %s, want %s", ToSNBT(tags), ToSNBT(want))
+	}
+}
+
+func TestParseSNBTSyntaxErrors(t *testing.T) {
+	tests := []struct {
+		snbt   string
+		offset int
+	}{
+		{`{a:"unterminated}`, 3},
+		{`{a:{b:1}`, 8},
+		{`{a:[1,2}`, 7},
+		{`{a:1]`, 4},
+		{`{a:[1,2b]}`, 6},
+		{`{a:[I;1,2b]}`, 8},
+		{`{a:1}}`, 5},
+		{`[1,2]`, 0},
+	}
+	for _, test := range tests {
+		_, err := ParseSNBT(test.snbt)
+		var syntaxErr *SNBTSyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("%s: error %v is no syntax error", test.snbt, err)
+			continue
+		}
+		if syntaxErr.Offset != test.offset {
+			t.Errorf("%s: error at offset %d, want %d: %v", test.snbt, syntaxErr.Offset, test.offset, err)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package nbt
+
+// Stats counts the nodes of each type in the tree below and including root.
+// Elements of arrays are counted as nodes of their element type, so an int
+// array of length n adds one NodeTypeIntArray and n NodeTypeInt.
+func Stats(root Node) map[NodeType]int {
+	counts := make(map[NodeType]int)
+	Walk(root, func(path string, node Node) error {
+		if node == nil {
+			return nil
+		}
+		counts[node.Type()]++
+		switch n := node.(type) {
+		case *ByteArrayNode:
+			counts[NodeTypeByte] += len(n.Values)
+		case *IntArrayNode:
+			counts[NodeTypeInt] += len(n.Values)
+		case *LongArrayNode:
+			counts[NodeTypeLong] += len(n.Values)
+		}
+		return nil
+	})
+	return counts
+}
+
+// EncodedSize returns the number of bytes the payload of node occupies in
+// uncompressed NBT, excluding the type and name of the node itself. Compared
+// to MemorySize it tells which parts of a chunk make a file large. The size
+// of nodes of custom types is unknown and counted as zero.
+func EncodedSize(node Node) int64 {
+	switch n := node.(type) {
+	case *ByteNode:
+		return 1
+	case *ShortNode:
+		return 2
+	case *IntNode, *FloatNode:
+		return 4
+	case *LongNode, *DoubleNode:
+		return 8
+	case *ByteArrayNode:
+		return 4 + int64(len(n.Values))
+	case *StringNode:
+		return 2 + int64(len(encodeModifiedUTF8(n.Value)))
+	case *ListNode:
+		size := int64(1 + 4)
+		for _, childNode := range n.Values {
+			size += EncodedSize(childNode)
+		}
+		return size
+	case *CompoundNode:
+		size := int64(1)
+		for key, childNode := range n.Values {
+			size += 1 + 2 + int64(len(encodeModifiedUTF8(key))) + EncodedSize(childNode)
+		}
+		return size
+	case *IntArrayNode:
+		return 4 + 4*int64(len(n.Values))
+	case *LongArrayNode:
+		return 4 + 8*int64(len(n.Values))
+	case *UnknownNode:
+		return int64(len(n.Raw))
+
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,38 @@
+package nbt
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	f, err := ParseSNBT(`{Name:"x",Heights:[I;1,2,3,4,5],Pos:[1.0d,2.0d],Blocks:[L;1L,2L],Biomes:[B;1b],Sections:[{Y:0b},{Y:1b}]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[NodeType]int{
+		NodeTypeCompound:  3,
+		NodeTypeString:    1,
+		NodeTypeIntArray:  1,
+		NodeTypeInt:       5,
+		NodeTypeList:      2,
+		NodeTypeDouble:    2,
+		NodeTypeLongArray: 1,
+		NodeTypeLong:      2,
+		NodeTypeByteArray: 1,
+		NodeTypeByte:      3,
+	}
+	if counts := Stats(f.Root); !maps.Equal(counts, want) {
+		t.Errorf("counts = %v, want %v", counts, want)
+	}
+
+	// the int array of length 5 takes its length and 4 bytes per element
+	if size := EncodedSize(f.Root.(*CompoundNode).Values["Heights"]); size != 4+5*4 {
+		t.Errorf("encoded size of int array = %d, want 24", size)
+	}
+	data := encodeFile(t, f)
+	// the file adds the type and empty name of the root compound
+	if size := EncodedSize(f.Root); size != int64(len(data)-3) {
+		t.Errorf("encoded size = %d, want %d", size, len(data)-3)
+	}
+}
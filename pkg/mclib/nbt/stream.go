@@ -0,0 +1,380 @@
+package nbt
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+type EventKind int
+
+const (
+	// EventStartCompound opens a compound that is closed by EventEnd.
+	EventStartCompound EventKind = iota
+	// EventStartList opens a list of ElemType and Len elements that is
+	// closed by EventEnd.
+	EventStartList
+	// EventValue holds any node that is not a list or compound.
+	EventValue
+	// EventEnd closes the innermost list or compound.
+	EventEnd
+)
+
+// Event describes a single step of a StreamReader.
+type Event struct {
+	Kind EventKind
+	// Name is the key of the node inside its compound or the name of the
+	// top-level compound. It is empty for list elements.
+	Name string
+	// Index is the position of the node inside its list.
+	Index int
+	// ElemType and Len describe the elements of a list.
+	ElemType NodeType
+	Len      int
+	// Value is set for EventValue.
+	Value Node
+}
+
+type streamFrame struct {
+	isList    bool
+	elemType  NodeType
+	remaining int
+	index     int
+}
+
+// StreamReader parses uncompressed NBT data step by step without building a
+// tree, so large files can be processed in constant memory.
+type StreamReader struct {
+	d       *decoder
+	started bool
+	stack   []streamFrame
+}
+
+func NewStreamReader(r io.Reader) *StreamReader {
+	return &StreamReader{d: &decoder{r: r, order: binary.BigEndian}}
+}
+
+// Next returns the next event. It returns io.EOF after the top-level compound
+// has been closed.
+func (s *StreamReader) Next() (Event, error) {
+	if !s.started {
+		s.started = true
+		nodeType, err := s.d.readRawNodeType()
+		if err != nil {
+			if err == ErrUnexpectedEOF {
+				return Event{}, ErrEmptyInput
+			}
+			return Event{}, err
+		}
+		if nodeType != NodeTypeCompound {
+			return Event{}, fmt.Errorf("%w, got type %v", ErrNotCompound, nodeType)
+		}
+		name, err := s.d.readRawString()
+		if err != nil {
+			return Event{}, err
+		}
+		s.stack = append(s.stack, streamFrame{})
+		return Event{Kind: EventStartCompound, Name: name}, nil
+	}
+	if len(s.stack) == 0 {
+		return Event{}, io.EOF
+	}
+
+	top := &s.stack[len(s.stack)-1]
+	if top.isList {
+		if top.remaining == 0 {
+			s.stack = s.stack[:len(s.stack)-1]
+			return Event{Kind: EventEnd}, nil
+		}
+		top.remaining--
+		index := top.index
+		top.index++
+		ev, err := s.readChild(top.elemType)
+		if err != nil {
+			return Event{}, fmt.Errorf("read list index %d: %w", index, err)
+		}
+		ev.Index = index
+		return ev, nil
+	}
+
+	nodeType, err := s.d.readRawNodeType()
+	if err != nil {
+		return Event{}, err
+	}
+	if nodeType == NodeTypeEnd {
+		s.stack = s.stack[:len(s.stack)-1]
+		return Event{Kind: EventEnd}, nil
+	}
+	name, err := s.d.readRawString()
+	if err != nil {
+		return Event{}, err
+	}
+	ev, err := s.readChild(nodeType)
+	if err != nil {
+		return Event{}, fmt.Errorf("read compound child %q: %w", name, err)
+	}
+	ev.Name = name
+	return ev, nil
+}
+
+func (s *StreamReader) readChild(nodeType NodeType) (Event, error) {
+	switch nodeType {
+	case NodeTypeCompound:
+		s.stack = append(s.stack, streamFrame{})
+		return Event{Kind: EventStartCompound}, nil
+
+	case NodeTypeList:
+		elemType, err := s.d.readRawNodeType()
+		if err != nil {
+			return Event{}, err
+		}
+		length, err := s.d.readRawInt()
+		if err != nil {
+			return Event{}, err
+		}
+		if !IsValidNodeType(elemType) {
+			return Event{}, fmt.Errorf("%w: element type %v", ErrInvalidList, elemType)
+		}
+		if length < 0 {
+			return Event{}, fmt.Errorf("%w: negative length %d", ErrInvalidList, length)
+		}
+		s.stack = append(s.stack, streamFrame{isList: true, elemType: elemType, remaining: int(length)})
+		return Event{Kind: EventStartList, ElemType: elemType, Len: int(length)}, nil
+
+	default:
+		node, err := s.d.readNodeOfType(nodeType)
+		if err != nil {
+			return Event{}, err
+		}
+		return Event{Kind: EventValue, Value: node}, nil
+	}
+}
+
+// Skip discards the rest of the innermost open list or compound including its
+// EventEnd without decoding the contained values.
+func (s *StreamReader) Skip() error {
+	if len(s.stack) == 0 {
+		return fmt.Errorf("no open container")
+	}
+
+	top := s.stack[len(s.stack)-1]
+	s.stack = s.stack[:len(s.stack)-1]
+	if top.isList {
+		for range top.remaining {
+			if err := s.d.skipNodeOfType(top.elemType); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return s.d.skipCompoundChildren()
+}
+
+// readRemaining reads the elements left in the innermost open list.
+func (s *StreamReader) readRemaining() ([]Node, error) {
+	top := s.stack[len(s.stack)-1]
+	if !top.isList {
+		return nil, fmt.Errorf("innermost container is not a list")
+	}
+	s.stack = s.stack[:len(s.stack)-1]
+
+	nodes := make([]Node, 0, top.remaining)
+	for i := range top.remaining {
+		node, err := s.d.readNodeOfType(top.elemType)
+		if err != nil {
+			return nil, fmt.Errorf("read list index %d: %w", top.index+i, err)
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+// skipNodeOfType discards the payload of a node.
+func (d *decoder) skipNodeOfType(nodeType NodeType) error {
+	if IsContainer(nodeType) {
+		if err := d.enterContainer(); err != nil {
+			return err
+		}
+		defer d.leaveContainer()
+	}
+	switch nodeType {
+	case NodeTypeByte:
+		return d.skipBytes(1)
+	case NodeTypeShort:
+		return d.skipBytes(2)
+	case NodeTypeInt, NodeTypeFloat:
+		return d.skipBytes(4)
+	case NodeTypeLong, NodeTypeDouble:
+		return d.skipBytes(8)
+	case NodeTypeByteArray:
+		return d.skipArray(1)
+	case NodeTypeIntArray:
+		return d.skipArray(4)
+	case NodeTypeLongArray:
+		return d.skipArray(8)
+	case NodeTypeString:
+		length, err := d.readRawUShort()
+		if err != nil {
+			return err
+		}
+		return d.skipBytes(int64(length))
+	case NodeTypeList:
+		elemType, err := d.readRawNodeType()
+		if err != nil {
+			return err
+		}
+		length, err := d.readRawInt()
+		if err != nil {
+			return err
+		}
+		if length > 0 && (!IsValidNodeType(elemType) || elemType == NodeTypeEnd) {
+			return fmt.Errorf("%w: element type %v", ErrInvalidList, elemType)
+		}
+		for range int(length) {
+			if err := d.skipNodeOfType(elemType); err != nil {
+				return err
+			}
+		}
+		return nil
+	case NodeTypeCompound:
+		return d.skipCompoundChildren()
+
+	default:
+		return fmt.Errorf("%w %v", ErrUnsupportedNodeType, nodeType)
+	}
+}
+
+func (d *decoder) skipCompoundChildren() error {
+	for {
+		nodeType, err := d.readRawNodeType()
+		if err != nil {
+			return err
+		}
+		if nodeType == NodeTypeEnd {
+			return nil
+		}
+		name, err := d.readRawString()
+		if err != nil {
+			return err
+		}
+		if err := d.skipNodeOfType(nodeType); err != nil {
+			return fmt.Errorf("skip compound child %q: %w", name, err)
+		}
+	}
+}
+
+func (d *decoder) skipArray(elemSize int64) error {
+	length, err := d.readRawInt()
+	if err != nil {
+		return err
+	}
+	if length < 0 {
+		return fmt.Errorf("%w: negative length %d", ErrInvalidArray, length)
+	}
+	return d.skipBytes(int64(length) * elemSize)
+}
+
+func (d *decoder) skipBytes(n int64) error {
+	written, err := io.CopyN(io.Discard, d.r, n)
+	d.offset += written
+	if err != nil {
+		if err == io.EOF {
+			return ErrUnexpectedEOF
+		}
+		return err
+	}
+	return nil
+}
+
+// StreamWriter writes uncompressed NBT data from a sequence of calls that
+// mirror the events of a StreamReader. The first call must open the
+// top-level compound.
+type StreamWriter struct {
+	e     *encoder
+	stack []streamFrame
+}
+
+func NewStreamWriter(w io.Writer) *StreamWriter {
+	return &StreamWriter{e: &encoder{w: w, order: binary.BigEndian}}
+}
+
+// writeHeader writes the type and name of a node unless it is a list element.
+func (s *StreamWriter) writeHeader(name string, nodeType NodeType) error {
+	if len(s.stack) == 0 {
+		if nodeType != NodeTypeCompound {
+			return fmt.Errorf("root node must be a compound")
+		}
+	} else if top := &s.stack[len(s.stack)-1]; top.isList {
+		if nodeType != top.elemType {
+			return fmt.Errorf("node type %v differs from list type %v", nodeType, top.elemType)
+		}
+		if top.remaining == 0 {
+			return fmt.Errorf("list already holds all declared elements")
+		}
+		top.remaining--
+		return nil
+	}
+
+	if err := s.e.writeRawByte(byte(nodeType)); err != nil {
+		return err
+	}
+	return s.e.writeRawString(name)
+}
+
+// StartCompound opens a compound. The name is ignored inside lists.
+func (s *StreamWriter) StartCompound(name string) error {
+	if err := s.writeHeader(name, NodeTypeCompound); err != nil {
+		return err
+	}
+	s.stack = append(s.stack, streamFrame{})
+	return nil
+}
+
+// StartList opens a list that must receive exactly length elements.
+func (s *StreamWriter) StartList(name string, elemType NodeType, length int) error {
+	if len(s.stack) == 0 {
+		return fmt.Errorf("root node must be a compound")
+	}
+	if err := s.writeHeader(name, NodeTypeList); err != nil {
+		return err
+	}
+	if err := s.e.writeRawByte(byte(elemType)); err != nil {
+		return err
+	}
+	if err := s.e.writeRawInt(int32(length)); err != nil {
+		return err
+	}
+	s.stack = append(s.stack, streamFrame{isList: true, elemType: elemType, remaining: length})
+	return nil
+}
+
+// WriteValue writes a complete node, which may also be a list or compound.
+func (s *StreamWriter) WriteValue(name string, node Node) error {
+	if len(s.stack) == 0 {
+		return fmt.Errorf("root node must be a compound")
+	}
+	if node == nil {
+		return fmt.Errorf("nil node")
+	}
+	if err := s.writeHeader(name, node.Type()); err != nil {
+		return err
+	}
+	return s.e.writeNode(node)
+}
+
+// End closes the innermost open list or compound.
+func (s *StreamWriter) End() error {
+	if len(s.stack) == 0 {
+		return fmt.Errorf("no open container")
+	}
+
+	top := s.stack[len(s.stack)-1]
+	s.stack = s.stack[:len(s.stack)-1]
+	if top.isList {
+		if top.remaining > 0 {
+			return fmt.Errorf("list is missing %d elements", top.remaining)
+		}
+		return nil
+	}
+	return s.e.writeRawByte(byte(NodeTypeEnd))
+}
@@ -0,0 +1,59 @@
+package nbt
+
+import (
+	"bytes"
+	"testing"
+)
+
+// unknownTagFile holds {a:1, u:<tag of type 99>, b:2}, where the payload of
+// the unknown tag is 01 02 03.
+var unknownTagFile = []byte{
+	0x0a, 0x00, 0x00,
+	0x03, 0x00, 0x01, 'a', 0x00, 0x00, 0x00, 0x01,
+	0x63, 0x00, 0x01, 'u', 0x01, 0x02, 0x03,
+	0x03, 0x00, 0x01, 'b', 0x00, 0x00, 0x00, 0x02,
+	0x00,
+}
+
+func TestUnknownTagRoundTrip(t *testing.T) {
+	f, err := ReadRawFromStreamWithOptions(bytes.NewReader(unknownTagFile), ReadOptions{SkipUnknownTags: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := f.Root.(*CompoundNode)
+	unknown, ok := root.Values["u"].(*UnknownNode)
+	if !ok {
+		t.Fatalf("u = %T, want *UnknownNode", root.Values["u"])
+	}
+	if unknown.TagType != 0x63 || !bytes.Equal(unknown.Raw, unknownTagFile[15:]) {
+		t.Errorf("unknown node = %v, raw %x", unknown.TagType, unknown.Raw)
+	}
+	if _, ok := root.Values["b"]; ok {
+		t.Errorf("b follows the unknown tag and must be part of its raw data")
+	}
+
+	var buf bytes.Buffer
+	if err := WriteToStream(&buf, f); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), unknownTagFile) {
+		t.Errorf("unedited output differs:\ngot  %x\nwant %x", buf.Bytes(), unknownTagFile)
+	}
+
+	root.Values["a"].(*IntNode).Value = 5
+	buf.Reset()
+	if err := WriteToStream(&buf, f); err != nil {
+		t.Fatal(err)
+	}
+	want := bytes.Clone(unknownTagFile)
+	want[10] = 5
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("edited output differs:\ngot  %x\nwant %x", buf.Bytes(), want)
+	}
+}
+
+func TestUnknownTagRejectedByDefault(t *testing.T) {
+	if _, err := ReadRawFromStream(bytes.NewReader(unknownTagFile)); err == nil {
+		t.Errorf("expected error for unknown tag type")
+	}
+}
@@ -0,0 +1,114 @@
+package nbt
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// uuidKeys are compound keys known to hold UUIDs. Keys ending in "UUID" are
+// recognized as well.
+var uuidKeys = map[string]bool{
+	"Owner":            true,
+	"Thrower":          true,
+	"Target":           true,
+	"LoveCause":        true,
+	"AngryAt":          true,
+	"ConversionPlayer": true,
+	"Trusted":          true,
+}
+
+func isUUIDKey(key string) bool {
+	return uuidKeys[key] || strings.HasSuffix(key, "UUID")
+}
+
+// ConvertUUIDsToString replaces all UUIDs stored as int arrays, the format
+// used since 1.16, by their hyphenated string form. It returns the number of
+// converted values.
+func ConvertUUIDsToString(f *File) int {
+	return convertUUIDs(f, func(node Node) (Node, bool) {
+		arrayNode, ok := node.(*IntArrayNode)
+		if !ok {
+			return nil, false
+		}
+		str, ok := uuidToString(arrayNode)
+		if !ok {
+			return nil, false
+		}
+		return &StringNode{Value: str}, true
+	})
+}
+
+// ConvertUUIDsToIntArray is the reverse of ConvertUUIDsToString.
+func ConvertUUIDsToIntArray(f *File) int {
+	return convertUUIDs(f, func(node Node) (Node, bool) {
+		str, ok := Str(node)
+		if !ok {
+			return nil, false
+		}
+		arrayNode, err := uuidFromString(str)
+		if err != nil {
+			return nil, false
+		}
+		return arrayNode, true
+	})
+}
+
+func convertUUIDs(f *File, convert func(node Node) (Node, bool)) int {
+	rootNode, ok := f.rootCompound()
+	if !ok {
+		return 0
+	}
+
+	converted := 0
+	Walk(rootNode, func(_ string, node Node) error {
+		compoundNode, ok := node.(*CompoundNode)
+		if !ok {
+			return nil
+		}
+		for key, childNode := range compoundNode.Values {
+			if !isUUIDKey(key) {
+				continue
+			}
+			if newNode, ok := convert(childNode); ok {
+				compoundNode.Values[key] = newNode
+				converted++
+			}
+		}
+		return nil
+	})
+	return converted
+}
+
+func uuidToString(n *IntArrayNode) (string, bool) {
+	if len(n.Values) != 4 {
+		return "", false
+	}
+	raw := make([]byte, 16)
+	for i, childNode := range n.Values {
+		val, ok := Int(childNode)
+		if !ok {
+			return "", false
+		}
+		binary.BigEndian.PutUint32(raw[4*i:], uint32(val))
+	}
+	str := hex.EncodeToString(raw)
+	return str[0:8] + "-" + str[8:12] + "-" + str[12:16] + "-" + str[16:20] + "-" + str[20:32], true
+}
+
+func uuidFromString(str string) (*IntArrayNode, error) {
+	if len(str) != 36 || str[8] != '-' || str[13] != '-' || str[18] != '-' || str[23] != '-' {
+		return nil, fmt.Errorf("malformed uuid %q", str)
+	}
+	raw, err := hex.DecodeString(strings.ReplaceAll(str, "-", ""))
+	if err != nil {
+		return nil, fmt.Errorf("malformed uuid %q: %w", str, err)
+	}
+
+	node := &IntArrayNode{Values: make([]Node, 4)}
+	for i := range node.Values {
+		node.Values[i] = &IntNode{Value: int32(binary.BigEndian.Uint32(raw[4*i:]))}
+	}
+	return node, nil
+}
@@ -0,0 +1,38 @@
+package nbt
+
+import "testing"
+
+func TestConvertUUIDs(t *testing.T) {
+	const snbt = `{UUID:[I;1,2,-1,-559038737],Owner:[I;0,0,0,255],Target:[I;1,2],Pos:[I;1,2,3,4],Passengers:[{UUID:[I;0,0,0,1]}],Name:"x"}`
+	f, err := ParseSNBT(snbt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if converted := ConvertUUIDsToString(f); converted != 3 {
+		t.Errorf("converted %d UUIDs to strings, want 3", converted)
+	}
+	want, err := ParseSNBT(`{UUID:"00000001-0000-0002-ffff-ffffdeadbeef",Owner:"00000000-0000-0000-0000-0000000000ff",Target:[I;1,2],Pos:[I;1,2,3,4],Passengers:[{UUID:"00000000-0000-0000-0000-000000000001"}],Name:"x"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Equal(f.Root, want.Root) {
+		t.Errorf("converted to %s", ToSNBT(f.Root))
+	}
+
+	if converted := ConvertUUIDsToIntArray(f); converted != 3 {
+		t.Errorf("converted %d UUIDs to int arrays, want 3", converted)
+	}
+	original, _ := ParseSNBT(snbt)
+	if !Equal(f.Root, original.Root) {
+		t.Errorf("converted back to %s", ToSNBT(f.Root))
+	}
+}
+
+func TestUUIDFromStringMalformed(t *testing.T) {
+	for _, str := range []string{"", "00000001-0000-0002-ffff", "0000000100000002ffffffffdeadbeef0000", "0000000g-0000-0002-ffff-ffffdeadbeef"} {
+		if _, err := uuidFromString(str); err == nil {
+			t.Errorf("expected error for %q", str)
+		}
+	}
+}
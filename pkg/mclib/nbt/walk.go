@@ -0,0 +1,49 @@
+package nbt
+
+import "strconv"
+
+// Walk visits root and all nodes below it depth-first, passing each node
+// along with its path like "Data.Player.Inventory[0]". Compound children are
+// visited in sorted key order. A node is visited before its children, so fn
+// may modify them. Walk stops at the first error returned by fn.
+func Walk(root Node, fn func(path string, node Node) error) error {
+	return walk("", root, fn)
+}
+
+func walk(path string, node Node, fn func(path string, node Node) error) error {
+	if err := fn(path, node); err != nil {
+		return err
+	}
+
+	switch n := node.(type) {
+	case *CompoundNode:
+		for _, key := range n.SortedKeys() {
+			childNode, ok := n.Values[key]
+			if !ok {
+				// removed while visiting a sibling
+				continue
+			}
+			if err := walk(childPath(path, key), childNode, fn); err != nil {
+				return err
+			}
+		}
+	case *ListNode:
+		for i, childNode := range n.Values {
+			if err := walk(indexPath(path, i), childNode, fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func childPath(path, key string) string {
+	if len(path) == 0 {
+		return key
+	}
+	return path + "." + key
+}
+
+func indexPath(path string, index int) string {
+	return path + "[" + strconv.Itoa(index) + "]"
+}
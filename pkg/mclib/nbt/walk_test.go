@@ -0,0 +1,62 @@
+package nbt
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestWalkPaths(t *testing.T) {
+	f := testInventoryFile(t)
+	var paths []string
+	err := Walk(f.Root, func(path string, node Node) error {
+		paths = append(paths, path)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"",
+		"Data",
+		"Data.Player",
+		"Data.Player.Inventory",
+		"Data.Player.Inventory[0]",
+		"Data.Player.Inventory[0].Count",
+		"Data.Player.Inventory[0].Slot",
+		"Data.Player.Inventory[0].id",
+		"Data.Player.Inventory[1]",
+		"Data.Player.Inventory[1].Count",
+		"Data.Player.Inventory[1].Slot",
+		"Data.Player.Inventory[1].id",
+		"Data.Player.Inventory[1].tag",
+		"Data.Player.Inventory[1].tag.display",
+		"Data.Player.Inventory[1].tag.display.Name",
+		"Data.Player.Pos",
+		"Data.Player.Pos[0]",
+		"Data.Player.Pos[1]",
+		"Data.Player.Pos[2]",
+	}
+	if !slices.Equal(paths, want) {
+		t.Errorf("paths = %q\nwant %q", paths, want)
+	}
+}
+
+func TestWalkStops(t *testing.T) {
+	f := testInventoryFile(t)
+	errStop := errors.New("stop")
+	visited := 0
+	err := Walk(f.Root, func(path string, node Node) error {
+		visited++
+		if path == "Data.Player.Inventory[0]" {
+			return errStop
+		}
+		return nil
+	})
+	if !errors.Is(err, errStop) {
+		t.Errorf("Walk = %v, want %v", err, errStop)
+	}
+	if visited != 5 {
+		t.Errorf("visited %d nodes, want 5", visited)
+	}
+}
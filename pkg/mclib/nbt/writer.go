@@ -0,0 +1,318 @@
+package nbt
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+)
+
+type WriteOptions struct {
+	// PruneEmpty omits empty lists and compounds, including compounds that
+	// only hold such values, from the output. This changes the shape of the
+	// written tree, so reading it back yields missing keys instead of empty
+	// values. Elements of lists are never omitted.
+	PruneEmpty bool
+	// Endianness is the byte order of the output, BigEndian by default.
+	Endianness Endianness
+	// Compression of the output, uncompressed by default. The game uses gzip
+	// for level.dat and player data, and zlib for region chunks.
+	Compression CompressionType
+}
+
+// WriteToFile writes f gzip-compressed like the game stores level.dat and
+// player data.
+func WriteToFile(file string, f *File) error {
+	return writeCompressedFile(file, f, CompressionGZip)
+}
+
+func WriteGZipToStream(w io.Writer, f *File) error {
+	return writeCompressed(w, f, CompressionGZip)
+}
+
+func WriteToStream(w io.Writer, f *File) error {
+	return WriteToStreamWithOptions(w, f, WriteOptions{})
+}
+
+func WriteToStreamWithOptions(w io.Writer, f *File, opts WriteOptions) error {
+	compressor, err := newCompressor(w, opts.Compression)
+	if err != nil {
+		return err
+	}
+	e := &encoder{w: compressor, order: opts.Endianness.byteOrder(), opts: opts}
+	if err := e.writeFile(f); err != nil {
+		return err
+	}
+	if err := compressor.Close(); err != nil {
+		return fmt.Errorf("flush compressed data: %w", err)
+	}
+	return nil
+}
+
+// WriteTo implements io.WriterTo by writing f as uncompressed NBT data. It
+// returns the number of bytes written.
+func (f *File) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	err := WriteToStream(cw, f)
+	return cw.n, err
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	return n, err
+}
+
+type encoder struct {
+	w     io.Writer
+	order binary.ByteOrder
+	opts  WriteOptions
+	// done is set once an UnknownNode has emitted the remaining output.
+	done bool
+	// sortKeys writes compound children in sorted key order instead of the
+	// order of CompoundNode.Keys.
+	sortKeys bool
+}
+
+func (e *encoder) writeFile(f *File) error {
+	rootNode, ok := f.Root.(*CompoundNode)
+	if !ok {
+		return fmt.Errorf("write nbt data: root node must be a compound")
+	}
+	if err := e.writeNamedNode(f.RootName, rootNode); err != nil {
+		return fmt.Errorf("write nbt data: %w", err)
+	}
+	return nil
+}
+
+func (e *encoder) writeRawByte(val byte) error {
+	_, err := e.w.Write([]byte{val})
+	return err
+}
+
+func (e *encoder) writeRawUShort(val uint16) error {
+	buf := make([]byte, 2)
+	e.order.PutUint16(buf, val)
+	_, err := e.w.Write(buf)
+	return err
+}
+
+func (e *encoder) writeRawInt(val int32) error {
+	buf := make([]byte, 4)
+	e.order.PutUint32(buf, uint32(val))
+	_, err := e.w.Write(buf)
+	return err
+}
+
+func (e *encoder) writeRawLong(val int64) error {
+	buf := make([]byte, 8)
+	e.order.PutUint64(buf, uint64(val))
+	_, err := e.w.Write(buf)
+	return err
+}
+
+func (e *encoder) writeRawString(val string) error {
+	data := encodeModifiedUTF8(val)
+	if len(data) > math.MaxUint16 {
+		return fmt.Errorf("string of length %d exceeds maximum length", len(data))
+	}
+	if err := e.writeRawUShort(uint16(len(data))); err != nil {
+		return err
+	}
+	_, err := e.w.Write(data)
+	return err
+}
+
+func (e *encoder) writeNamedNode(name string, node Node) error {
+	if node == nil {
+		return fmt.Errorf("nil node")
+	}
+	if err := e.writeRawByte(byte(node.Type())); err != nil {
+		return err
+	}
+	if err := e.writeRawString(name); err != nil {
+		return err
+	}
+	return e.writeNode(node)
+}
+
+func (e *encoder) writeNode(node Node) error {
+	switch n := node.(type) {
+	case *ByteNode:
+		return e.writeRawByte(n.Value)
+	case *ShortNode:
+		return e.writeRawUShort(uint16(n.Value))
+	case *IntNode:
+		return e.writeRawInt(n.Value)
+	case *LongNode:
+		return e.writeRawLong(n.Value)
+	case *FloatNode:
+		return e.writeRawInt(int32(math.Float32bits(n.Value)))
+	case *DoubleNode:
+		return e.writeRawLong(int64(math.Float64bits(n.Value)))
+	case *ByteArrayNode:
+		return e.writeByteArrayNode(n)
+	case *StringNode:
+		return e.writeRawString(n.Value)
+	case *ListNode:
+		return e.writeListNode(n)
+	case *CompoundNode:
+		return e.writeCompoundNode(n)
+	case *IntArrayNode:
+		return e.writeIntArrayNode(n)
+	case *LongArrayNode:
+		return e.writeLongArrayNode(n)
+	case *UnknownNode:
+		return e.writeUnknownNode(n)
+
+	default:
+		return fmt.Errorf("unsupported node %T", node)
+	}
+}
+
+func (e *encoder) writeListNode(n *ListNode) error {
+	childNodeType := n.ElementType
+	if len(n.Values) > 0 && n.Values[0] != nil {
+		childNodeType = n.Values[0].Type()
+	}
+	if err := e.writeRawByte(byte(childNodeType)); err != nil {
+		return err
+	}
+	if err := e.writeRawInt(int32(len(n.Values))); err != nil {
+		return err
+	}
+
+	for i, childNode := range n.Values {
+		if childNode == nil {
+			return fmt.Errorf("write list index %d: nil node", i)
+		}
+		if childNode.Type() != childNodeType {
+			return fmt.Errorf("write list index %d: node type %v differs from list type %v", i, childNode.Type(), childNodeType)
+		}
+		if _, ok := childNode.(*UnknownNode); ok {
+			return fmt.Errorf("write list index %d: unknown tag cannot be written inside a list", i)
+		}
+		if err := e.writeNode(childNode); err != nil {
+			return fmt.Errorf("write list index %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (e *encoder) writeCompoundNode(n *CompoundNode) error {
+	if err := e.writeCompoundChildren(n); err != nil {
+		return err
+	}
+	if e.done {
+		// the end tag is already part of the raw unknown data
+		return nil
+	}
+	return e.writeRawByte(byte(NodeTypeEnd))
+}
+
+func (e *encoder) writeCompoundChildren(n *CompoundNode) error {
+	keys := n.Keys()
+	if e.sortKeys {
+		keys = n.SortedKeys()
+	}
+	// unknown nodes carry the remaining output and are therefore written last
+	for i, childName := range keys {
+		if holdsUnknownNode(n.Values[childName]) {
+			keys = append(slices.Delete(keys, i, i+1), childName)
+			break
+		}
+	}
+
+	for _, childName := range keys {
+		if e.opts.PruneEmpty && isPrunable(n.Values[childName]) {
+			continue
+		}
+		if err := e.writeNamedNode(childName, n.Values[childName]); err != nil {
+			return fmt.Errorf("write compound child %q: %w", childName, err)
+		}
+		if e.done {
+			return nil
+		}
+	}
+	return nil
+}
+
+func (e *encoder) writeByteArrayNode(n *ByteArrayNode) error {
+	if err := e.writeRawInt(int32(len(n.Values))); err != nil {
+		return err
+	}
+	_, err := e.w.Write(n.Values)
+	return err
+}
+
+func (e *encoder) writeIntArrayNode(n *IntArrayNode) error {
+	if err := e.writeRawInt(int32(len(n.Values))); err != nil {
+		return err
+	}
+	for i, childNode := range n.Values {
+		intNode, ok := childNode.(*IntNode)
+		if !ok {
+			return fmt.Errorf("write list index %d: int array contains %T", i, childNode)
+		}
+		if err := e.writeRawInt(intNode.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *encoder) writeLongArrayNode(n *LongArrayNode) error {
+	if err := e.writeRawInt(int32(len(n.Values))); err != nil {
+		return err
+	}
+	for _, val := range n.Values {
+		if err := e.writeRawLong(val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *encoder) writeUnknownNode(n *UnknownNode) error {
+	if _, err := e.w.Write(n.Raw); err != nil {
+		return err
+	}
+	e.done = true
+	return nil
+}
+
+func holdsUnknownNode(node Node) bool {
+	switch n := node.(type) {
+	case *UnknownNode:
+		return true
+	case *CompoundNode:
+		for _, childNode := range n.Values {
+			if holdsUnknownNode(childNode) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isPrunable reports whether a node is omitted with WriteOptions.PruneEmpty.
+func isPrunable(node Node) bool {
+	switch n := node.(type) {
+	case *ListNode:
+		return len(n.Values) == 0
+	case *CompoundNode:
+		for _, childNode := range n.Values {
+			if !isPrunable(childNode) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,196 @@
+package nbt
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWritePruneEmpty(t *testing.T) {
+	f, err := ParseSNBT(`{a:{},b:[],c:{d:{},e:[]},f:{g:1b,h:{}},i:[{},{}],j:[B;],k:""}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteToStreamWithOptions(&buf, f, WriteOptions{PruneEmpty: true}); err != nil {
+		t.Fatal(err)
+	}
+	readFile, err := ReadFromBytes(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// list elements, arrays and strings are kept even if empty
+	want, err := ParseSNBT(`{f:{g:1b},i:[{},{}],j:[B;],k:""}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Equal(readFile.Root, want.Root) {
+		t.Errorf("pruned tree = %s, want %s", ToSNBT(readFile.Root), ToSNBT(want.Root))
+	}
+
+	// the tree itself is not modified
+	if keys := f.Root.(*CompoundNode).Keys(); len(keys) != 7 {
+		t.Errorf("written tree lost keys: %v", keys)
+	}
+
+	buf.Reset()
+	if err := WriteToStream(&buf, f); err != nil {
+		t.Fatal(err)
+	}
+	readFile, err = ReadFromBytes(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Equal(readFile.Root, f.Root) {
+		t.Errorf("empty containers are omitted without PruneEmpty: %s", ToSNBT(readFile.Root))
+	}
+}
+
+// readGZipFile returns the decompressed contents of a gzip file.
+func readGZipFile(t testing.TB, path string) []byte {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	r, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestLevelDatRoundTrip(t *testing.T) {
+	f, err := ReadFromFile("testdata/level.dat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the fixture holds a node of every type, so every writer branch is used
+	stats := Stats(f.Root)
+	for nodeType := NodeTypeByte; nodeType <= NodeTypeLongArray; nodeType++ {
+		if stats[nodeType] == 0 {
+			t.Errorf("fixture does not contain a %v", nodeType)
+		}
+	}
+
+	out := filepath.Join(t.TempDir(), "level.dat")
+	if err := WriteToFile(out, f); err != nil {
+		t.Fatal(err)
+	}
+	want := readGZipFile(t, "testdata/level.dat")
+	if got := readGZipFile(t, out); !bytes.Equal(got, want) {
+		t.Errorf("written level.dat differs from the fixture after decompression")
+	}
+}
+
+func TestWriteCompressions(t *testing.T) {
+	f := testFile(t)
+	for _, compression := range []CompressionType{CompressionNone, CompressionGZip, CompressionZlib} {
+		var buf bytes.Buffer
+		if err := WriteToStreamWithOptions(&buf, f, WriteOptions{Compression: compression}); err != nil {
+			t.Fatal(err)
+		}
+		if detected := detectCompression(buf.Bytes()); detected != compression {
+			t.Errorf("written with %v instead of %v", detected, compression)
+		}
+		readFile, err := ReadFromStream(&buf)
+		if err != nil {
+			t.Fatalf("%v: %v", compression, err)
+		}
+		if !Equal(readFile.Root, f.Root) {
+			t.Errorf("%v: read %s", compression, ToSNBT(readFile.Root))
+		}
+	}
+
+	if err := WriteToStreamWithOptions(io.Discard, f, WriteOptions{Compression: 7}); err == nil {
+		t.Errorf("expected error for unsupported compression")
+	}
+
+	// like vanilla level.dat, files are written with gzip
+	path := filepath.Join(t.TempDir(), "level.dat")
+	if err := WriteToFile(path, f); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if detected := detectCompression(data); detected != CompressionGZip {
+		t.Errorf("WriteToFile used %v", detected)
+	}
+}
+
+var (
+	_ io.WriterTo   = (*File)(nil)
+	_ io.ReaderFrom = (*File)(nil)
+)
+
+func TestFileWriteToReadFrom(t *testing.T) {
+	f := testFile(t)
+	want := encodeFile(t, f)
+
+	var buf bytes.Buffer
+	n, err := f.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(len(want)) || n != int64(buf.Len()) {
+		t.Errorf("WriteTo wrote %d bytes, reports %d, want %d", buf.Len(), n, len(want))
+	}
+
+	// trailing data is left unread
+	r := bytes.NewReader(append(bytes.Clone(want), "trailer"...))
+	var readFile File
+	n, err = readFile.ReadFrom(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(len(want)) {
+		t.Errorf("ReadFrom reports %d bytes, want %d", n, len(want))
+	}
+	if r.Len() != len("trailer") {
+		t.Errorf("ReadFrom consumed %d bytes", r.Size()-int64(r.Len()))
+	}
+	if !Equal(readFile.Root, f.Root) {
+		t.Errorf("read %s", ToSNBT(readFile.Root))
+	}
+
+	n, err = readFile.ReadFrom(bytes.NewReader(want[:10]))
+	if err == nil || n != 10 {
+		t.Errorf("ReadFrom of truncated data = %d, %v, want 10 with error", n, err)
+	}
+}
+
+func TestRootNameRoundTrip(t *testing.T) {
+	for _, rootName := range []string{"", "Level", "名前"} {
+		f := testFile(t)
+		f.RootName = rootName
+
+		path := filepath.Join(t.TempDir(), "level.dat")
+		if err := WriteToFile(path, f); err != nil {
+			t.Fatal(err)
+		}
+		readFile, err := ReadFromFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if readFile.RootName != rootName {
+			t.Errorf("root name = %q, want %q", readFile.RootName, rootName)
+		}
+
+		// the name is stored right after the type of the root compound
+		data := readGZipFile(t, path)
+		if !bytes.HasPrefix(data[3:], []byte(rootName)) || int(data[1])<<8|int(data[2]) != len(rootName) {
+			t.Errorf("written header %x does not hold root name %q", data[:3+len(rootName)], rootName)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package region
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/sbreitf1/mctool/pkg/mclib/nbt"
+)
+
+// ChunkResult holds the outcome of reading a single chunk.
+type ChunkResult struct {
+	Pos  ChunkPos
+	File *nbt.File
+	Err  error
+}
+
+type chunkJob struct {
+	index       int
+	data        []byte
+	compression byte
+}
+
+// ReadAllChunks reads all present chunks of the region and parses them using
+// the given number of workers. The chunk data is read from the file serially
+// while decompression and parsing happen concurrently. Results are returned in
+// the order of Chunks, failed chunks carry their error in the result.
+func (r *Region) ReadAllChunks(workers int) ([]ChunkResult, error) {
+	if workers < 1 {
+		return nil, fmt.Errorf("invalid worker count %d", workers)
+	}
+
+	chunks := r.Chunks()
+	results := make([]ChunkResult, len(chunks))
+	jobs := make(chan chunkJob)
+
+	var wg sync.WaitGroup
+	for range min(workers, len(chunks)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				pos := results[job.index].Pos
+				f, err := parseChunk(job.data, job.compression)
+				if err != nil {
+					results[job.index].Err = fmt.Errorf("chunk %d,%d: %w", pos.X, pos.Z, err)
+					continue
+				}
+				results[job.index].File = f
+			}
+		}()
+	}
+
+	for i, pos := range chunks {
+		results[i].Pos = pos
+		data, compression, err := r.readChunkData(pos.X, pos.Z)
+		if err != nil {
+			results[i].Err = fmt.Errorf("chunk %d,%d: %w", pos.X, pos.Z, err)
+			continue
+		}
+		jobs <- chunkJob{index: i, data: data, compression: compression}
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results, nil
+}
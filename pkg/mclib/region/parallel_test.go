@@ -0,0 +1,45 @@
+package region
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/sbreitf1/mctool/pkg/mclib/nbt"
+)
+
+func TestReadAllChunks(t *testing.T) {
+	chunks := make(map[ChunkPos]string)
+	for i := range 20 {
+		pos := ChunkPos{X: (i * 7) % 32, Z: i}
+		chunks[pos] = fmt.Sprintf(`{xPos:%d,zPos:%d}`, pos.X, pos.Z)
+	}
+	r := openTestRegion(t, writeTestRegion(t, chunks))
+
+	for _, workers := range []int{1, 2, 8, 64} {
+		results, err := r.ReadAllChunks(workers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(results) != len(chunks) {
+			t.Fatalf("%d workers: got %d results, want %d", workers, len(results), len(chunks))
+		}
+		for i, result := range results {
+			if result.Pos != r.Chunks()[i] {
+				t.Errorf("%d workers: result %d is chunk %v, want %v", workers, i, result.Pos, r.Chunks()[i])
+			}
+			if result.Err != nil {
+				t.Errorf("%d workers: chunk %v: %v", workers, result.Pos, result.Err)
+				continue
+			}
+			x, _ := nbt.Int(result.File.Root.(*nbt.CompoundNode).Values["xPos"])
+			z, _ := nbt.Int(result.File.Root.(*nbt.CompoundNode).Values["zPos"])
+			if int(x) != result.Pos.X || int(z) != result.Pos.Z {
+				t.Errorf("%d workers: chunk %v holds position %d,%d", workers, result.Pos, x, z)
+			}
+		}
+	}
+
+	if _, err := r.ReadAllChunks(0); err == nil {
+		t.Errorf("expected error for zero workers")
+	}
+}
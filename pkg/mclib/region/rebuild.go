@@ -0,0 +1,106 @@
+package region
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/sbreitf1/mctool/pkg/mclib/nbt"
+)
+
+// RebuildRegionHeader reconstructs the location and timestamp tables of a
+// region file by scanning all sectors for parseable chunks. Chunks are
+// assigned to their slot by the position stored inside them. If a slot is
+// found more than once, for example because the game left an outdated copy
+// behind, the chunk with the highest LastUpdate is used. Timestamps are set
+// to the current time.
+func RebuildRegionHeader(path string) error {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return fmt.Errorf("open region file: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("stat region file: %w", err)
+	}
+	region := &Region{file: f}
+	sectorCount := info.Size() / SectorSize
+
+	lastUpdates := make(map[int]int64)
+	for sector := int64(headerSize / SectorSize); sector < sectorCount; {
+		index, sectors, lastUpdate, ok := region.scanSector(sector, sectorCount)
+		if !ok {
+			sector++
+			continue
+		}
+		if prev, found := lastUpdates[index]; !found || lastUpdate > prev {
+			region.locations[index] = uint32(sector<<8 | sectors)
+			lastUpdates[index] = lastUpdate
+		}
+		sector += sectors
+	}
+
+	header := make([]byte, headerSize)
+	timestamp := uint32(time.Now().Unix())
+	for i, location := range region.locations {
+		binary.BigEndian.PutUint32(header[4*i:], location)
+		if location != 0 {
+			binary.BigEndian.PutUint32(header[SectorSize+4*i:], timestamp)
+		}
+	}
+	if _, err := f.WriteAt(header, 0); err != nil {
+		return fmt.Errorf("write region header: %w", err)
+	}
+	return nil
+}
+
+// scanSector checks whether a chunk starts at the given sector and returns its
+// slot index, the number of sectors it occupies and its LastUpdate.
+func (r *Region) scanSector(sector, sectorCount int64) (int, int64, int64, bool) {
+	header := make([]byte, 5)
+	if _, err := r.file.ReadAt(header, sector*SectorSize); err != nil {
+		return 0, 0, 0, false
+	}
+	length := int64(binary.BigEndian.Uint32(header))
+	sectors := (length + 4 + SectorSize - 1) / SectorSize
+	if length < 1 || sectors > 0xff || sector+sectors > sectorCount {
+		return 0, 0, 0, false
+	}
+
+	data := make([]byte, length-1)
+	if _, err := r.file.ReadAt(data, sector*SectorSize+5); err != nil && err != io.EOF {
+		return 0, 0, 0, false
+	}
+	chunk, err := parseChunk(data, header[4])
+	if err != nil {
+		return 0, 0, 0, false
+	}
+
+	prefix := ""
+	if chunk.Query("Level").Len() > 0 {
+		prefix = "Level."
+	}
+	x, okX := queryInt(chunk, prefix+"xPos")
+	z, okZ := queryInt(chunk, prefix+"zPos")
+	if !okX || !okZ {
+		return 0, 0, 0, false
+	}
+	var lastUpdate int64
+	for _, node := range chunk.Query(prefix + "LastUpdate").Nodes() {
+		lastUpdate, _ = nbt.Long(node)
+	}
+
+	index := int(x&(chunksPerSide-1)) + int(z&(chunksPerSide-1))*chunksPerSide
+	return index, sectors, lastUpdate, true
+}
+
+func queryInt(f *nbt.File, path string) (int32, bool) {
+	for _, node := range f.Query(path).Nodes() {
+		return nbt.Int(node)
+	}
+	return 0, false
+}
@@ -0,0 +1,48 @@
+package region
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/sbreitf1/mctool/pkg/mclib/nbt"
+)
+
+func TestRebuildRegionHeader(t *testing.T) {
+	// the header is lost, chunk 1,0 is stored twice and one sector is garbage
+	data := make([]byte, headerSize)
+	data = writeChunkSectors(data, encodeChunk(t, `{xPos:1,zPos:0,LastUpdate:200L,Status:"new"}`), CompressionZlib)
+	data = append(data, make([]byte, SectorSize)...)
+	data = writeChunkSectors(data, encodeChunk(t, `{Level:{xPos:-31,zPos:2,LastUpdate:5L}}`), CompressionZlib)
+	data = writeChunkSectors(data, encodeChunk(t, `{xPos:1,zPos:0,LastUpdate:100L,Status:"old"}`), CompressionZlib)
+	data = writeChunkSectors(data, encodeChunk(t, `{zPos:0}`), CompressionZlib)
+	path := filepath.Join(t.TempDir(), "r.0.0.mca")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RebuildRegionHeader(path); err != nil {
+		t.Fatal(err)
+	}
+	r := openTestRegion(t, path)
+	if chunks, want := r.Chunks(), []ChunkPos{{X: 1, Z: 0}, {X: 1, Z: 2}}; !slices.Equal(chunks, want) {
+		t.Fatalf("chunks = %v, want %v", chunks, want)
+	}
+
+	chunk, err := r.ReadChunk(1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status, _ := nbt.Str(chunk.Root.(*nbt.CompoundNode).Values["Status"]); status != "new" {
+		t.Errorf("chunk 1,0 has status %q, want the newer copy", status)
+	}
+	if _, err := r.ReadChunk(1, 2); err != nil {
+		t.Errorf("chunk 1,2: %v", err)
+	}
+	for i, timestamp := range r.timestamps {
+		if (timestamp != 0) != (r.locations[i] != 0) {
+			t.Errorf("slot %d has location %x and timestamp %d", i, r.locations[i], timestamp)
+		}
+	}
+}
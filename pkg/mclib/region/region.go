@@ -0,0 +1,207 @@
+package region
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sbreitf1/mctool/pkg/mclib/nbt"
+)
+
+const (
+	SectorSize = 4096
+
+	chunksPerSide = 32
+	chunkCount    = chunksPerSide * chunksPerSide
+	headerSize    = 2 * SectorSize
+)
+
+const (
+	CompressionGZip         byte = 1
+	CompressionZlib         byte = 2
+	CompressionUncompressed byte = 3
+
+	compressionExternalFlag byte = 0x80
+)
+
+var ErrChunkNotPresent = errors.New("chunk not present")
+
+// ChunkPos addresses a chunk. Region methods use local coordinates in the
+// range 0 to 31.
+type ChunkPos struct {
+	X, Z int
+}
+
+type Region struct {
+	file       *os.File
+	locations  [chunkCount]uint32
+	timestamps [chunkCount]uint32
+}
+
+func OpenRegion(file string) (*Region, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("open region file: %w", err)
+	}
+
+	region, err := readRegionHeader(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("read region header: %w", err)
+	}
+	return region, nil
+}
+
+func readRegionHeader(f *os.File) (*Region, error) {
+	region := &Region{file: f}
+
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(f, header); err != nil {
+		if err == io.EOF {
+			// the game occasionally leaves empty region files behind
+			return region, nil
+		}
+		return nil, err
+	}
+
+	for i := range chunkCount {
+		region.locations[i] = binary.BigEndian.Uint32(header[4*i:])
+		region.timestamps[i] = binary.BigEndian.Uint32(header[SectorSize+4*i:])
+	}
+	return region, nil
+}
+
+func (r *Region) Close() error {
+	return r.file.Close()
+}
+
+// Chunks returns the positions of all populated chunk slots, see HasChunk.
+func (r *Region) Chunks() []ChunkPos {
+	chunks := make([]ChunkPos, 0)
+	for i, location := range r.locations {
+		if locationPresent(location) {
+			chunks = append(chunks, ChunkPos{X: i % chunksPerSide, Z: i / chunksPerSide})
+		}
+	}
+	return chunks
+}
+
+// ForEachChunk reads the chunks of the region in the order of Chunks and calls
+// fn for each of them. Slots without chunk data are skipped, while other read
+// errors and errors returned by fn stop the iteration and are returned.
+func (r *Region) ForEachChunk(fn func(pos ChunkPos, f *nbt.File) error) error {
+	for _, pos := range r.Chunks() {
+		f, err := r.ReadChunk(pos.X, pos.Z)
+		if errors.Is(err, ErrChunkNotPresent) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(pos, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HasChunk reports whether the chunk slot at the local position is populated.
+// It only consults the location table and never reads chunk data.
+func (r *Region) HasChunk(localX, localZ int) bool {
+	location, err := r.location(localX, localZ)
+	return err == nil && locationPresent(location)
+}
+
+// locationPresent reports whether a location table entry points to chunk
+// data, which requires both a sector offset and a sector count.
+func locationPresent(location uint32) bool {
+	return location>>8 != 0 && location&0xff != 0
+}
+
+func (r *Region) ReadChunk(localX, localZ int) (*nbt.File, error) {
+	data, compression, err := r.readChunkData(localX, localZ)
+	if err != nil {
+		return nil, fmt.Errorf("chunk %d,%d: %w", localX, localZ, err)
+	}
+	f, err := parseChunk(data, compression)
+	if err != nil {
+		return nil, fmt.Errorf("chunk %d,%d: %w", localX, localZ, err)
+	}
+	return f, nil
+}
+
+func (r *Region) location(localX, localZ int) (uint32, error) {
+	if localX < 0 || localX >= chunksPerSide || localZ < 0 || localZ >= chunksPerSide {
+		return 0, fmt.Errorf("position out of region bounds")
+	}
+	return r.locations[localX+localZ*chunksPerSide], nil
+}
+
+// readChunkData returns the still compressed payload of a chunk.
+func (r *Region) readChunkData(localX, localZ int) ([]byte, byte, error) {
+	location, err := r.location(localX, localZ)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !locationPresent(location) {
+		return nil, 0, ErrChunkNotPresent
+	}
+	offset, sectors := int64(location>>8), int64(location&0xff)
+	if offset < headerSize/SectorSize {
+		return nil, 0, fmt.Errorf("sector offset %d overlaps header", offset)
+	}
+
+	header := make([]byte, 5)
+	if _, err := r.file.ReadAt(header, offset*SectorSize); err != nil {
+		return nil, 0, fmt.Errorf("read chunk header: %w", err)
+	}
+	length := int64(binary.BigEndian.Uint32(header))
+	compression := header[4]
+	if length < 1 || length+4 > sectors*SectorSize {
+		return nil, 0, fmt.Errorf("invalid chunk length %d", length)
+	}
+	if compression&compressionExternalFlag != 0 {
+		return nil, 0, fmt.Errorf("external chunk files are not supported")
+	}
+
+	data := make([]byte, length-1)
+	if _, err := r.file.ReadAt(data, offset*SectorSize+5); err != nil {
+		return nil, 0, fmt.Errorf("read chunk data: %w", err)
+	}
+	return data, compression, nil
+}
+
+func parseChunk(data []byte, compression byte) (*nbt.File, error) {
+	r, err := decompressChunk(data, compression)
+	if err != nil {
+		return nil, err
+	}
+	return nbt.ReadRawFromStream(r)
+}
+
+func decompressChunk(data []byte, compression byte) (io.Reader, error) {
+	switch compression {
+	case CompressionGZip:
+		gzipReader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("open gzip reader: %w", err)
+		}
+		return gzipReader, nil
+	case CompressionZlib:
+		zlibReader, err := zlib.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("open zlib reader: %w", err)
+		}
+		return zlibReader, nil
+	case CompressionUncompressed:
+		return bytes.NewReader(data), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported chunk compression %d", compression)
+	}
+}
@@ -0,0 +1,230 @@
+package region
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/sbreitf1/mctool/pkg/mclib/nbt"
+)
+
+// encodeChunk returns the zlib compressed NBT data of the SNBT compound.
+func encodeChunk(t *testing.T, snbt string) []byte {
+	t.Helper()
+	f, err := nbt.ParseSNBT(snbt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	if err := nbt.WriteToStream(w, f); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// writeTestRegion writes a region file holding the chunks given as SNBT at
+// their local positions and returns its path. Chunks are stored in slot order,
+// each one starting at a new sector.
+func writeTestRegion(t *testing.T, chunks map[ChunkPos]string) string {
+	t.Helper()
+
+	positions := make([]ChunkPos, 0, len(chunks))
+	for pos := range chunks {
+		positions = append(positions, pos)
+	}
+	slices.SortFunc(positions, func(a, b ChunkPos) int {
+		return (a.X + a.Z*chunksPerSide) - (b.X + b.Z*chunksPerSide)
+	})
+
+	data := make([]byte, headerSize)
+	for _, pos := range positions {
+		payload := encodeChunk(t, chunks[pos])
+		sector := len(data) / SectorSize
+		sectors := (len(payload) + 5 + SectorSize - 1) / SectorSize
+		binary.BigEndian.PutUint32(data[4*(pos.X+pos.Z*chunksPerSide):], uint32(sector<<8|sectors))
+		data = writeChunkSectors(data, payload, CompressionZlib)
+	}
+
+	path := filepath.Join(t.TempDir(), "r.0.0.mca")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// writeChunkSectors appends the header and payload of a chunk to data,
+// padded to full sectors.
+func writeChunkSectors(data, payload []byte, compression byte) []byte {
+	data = binary.BigEndian.AppendUint32(data, uint32(len(payload)+1))
+	data = append(data, compression)
+	data = append(data, payload...)
+	if rest := len(data) % SectorSize; rest != 0 {
+		data = append(data, make([]byte, SectorSize-rest)...)
+	}
+	return data
+}
+
+func openTestRegion(t *testing.T, path string) *Region {
+	t.Helper()
+	r, err := OpenRegion(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { r.Close() })
+	return r
+}
+
+func TestHasChunk(t *testing.T) {
+	path := writeTestRegion(t, map[ChunkPos]string{
+		{X: 0, Z: 0}:   `{DataVersion:3953}`,
+		{X: 5, Z: 1}:   `{DataVersion:3953}`,
+		{X: 31, Z: 31}: `{DataVersion:3953}`,
+	})
+	// a location with an offset, but no sectors, is not a chunk either
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	binary.BigEndian.PutUint32(data[4*(2+3*chunksPerSide):], 2<<8)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r := openTestRegion(t, path)
+
+	for _, pos := range []ChunkPos{{X: 0, Z: 0}, {X: 5, Z: 1}, {X: 31, Z: 31}} {
+		if !r.HasChunk(pos.X, pos.Z) {
+			t.Errorf("chunk %d,%d is missing", pos.X, pos.Z)
+		}
+	}
+	for _, pos := range []ChunkPos{{X: 1, Z: 0}, {X: 2, Z: 3}, {X: 30, Z: 31}, {X: -1, Z: 0}, {X: 0, Z: 32}} {
+		if r.HasChunk(pos.X, pos.Z) {
+			t.Errorf("chunk %d,%d is present", pos.X, pos.Z)
+		}
+	}
+	want := []ChunkPos{{X: 0, Z: 0}, {X: 5, Z: 1}, {X: 31, Z: 31}}
+	if chunks := r.Chunks(); !slices.Equal(chunks, want) {
+		t.Errorf("chunks = %v, want %v", chunks, want)
+	}
+}
+
+func TestReadChunkBlockEntities(t *testing.T) {
+	r := openTestRegion(t, "testdata/r.0.0.mca")
+
+	f, err := r.ReadChunk(0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blockEntities, ok := f.Root.(*nbt.CompoundNode).List("block_entities")
+	if !ok {
+		t.Fatal("missing block_entities")
+	}
+	compounds, err := blockEntities.Compounds()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(compounds) != 1 {
+		t.Fatalf("%d block entities, want 1", len(compounds))
+	}
+	chest := compounds[0]
+	if id, _ := chest.Str("id"); id != "minecraft:chest" {
+		t.Errorf("id = %q, want minecraft:chest", id)
+	}
+	x, _ := chest.Int("x")
+	y, _ := chest.Int("y")
+	z, _ := chest.Int("z")
+	if x != 3 || y != 64 || z != 5 {
+		t.Errorf("chest at %d,%d,%d, want 3,64,5", x, y, z)
+	}
+	items, _ := chest.List("Items")
+	if items == nil || len(items.Values) != 2 {
+		t.Errorf("chest items = %v", items)
+	}
+
+	f, err = r.ReadChunk(4, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, node := range f.Query("block_entities[*].id").Nodes() {
+		id, _ := nbt.Str(node)
+		ids = append(ids, id)
+	}
+	if want := []string{"minecraft:furnace", "minecraft:sign"}; !slices.Equal(ids, want) {
+		t.Errorf("block entities of chunk 4,7 = %v, want %v", ids, want)
+	}
+
+	if _, err := r.ReadChunk(2, 0); !errors.Is(err, ErrChunkNotPresent) {
+		t.Errorf("read absent chunk: %v, want %v", err, ErrChunkNotPresent)
+	}
+	if _, err := r.ReadChunk(32, 0); err == nil {
+		t.Errorf("expected error for chunk out of region bounds")
+	}
+}
+
+func TestForEachChunk(t *testing.T) {
+	r := openTestRegion(t, "testdata/r.0.0.mca")
+
+	want := []ChunkPos{{X: 0, Z: 0}, {X: 1, Z: 0}, {X: 4, Z: 7}}
+	if chunks := r.Chunks(); !slices.Equal(chunks, want) {
+		t.Errorf("chunks = %v, want %v", chunks, want)
+	}
+
+	var visited []ChunkPos
+	err := r.ForEachChunk(func(pos ChunkPos, f *nbt.File) error {
+		visited = append(visited, pos)
+		if x, _ := f.Root.(*nbt.CompoundNode).Int("xPos"); int(x) != pos.X {
+			t.Errorf("chunk %v has xPos %d", pos, x)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(visited, want) {
+		t.Errorf("visited %v, want %v", visited, want)
+	}
+
+	errStop := errors.New("stop")
+	count := 0
+	err = r.ForEachChunk(func(pos ChunkPos, f *nbt.File) error {
+		count++
+		return errStop
+	})
+	if !errors.Is(err, errStop) || count != 1 {
+		t.Errorf("ForEachChunk = %v after %d chunks, want %v after 1", err, count, errStop)
+	}
+}
+
+func TestForEachChunkSkipsEmptySlots(t *testing.T) {
+	path := writeTestRegion(t, map[ChunkPos]string{{X: 0, Z: 0}: `{xPos:0}`})
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// an offset without sectors holds no chunk
+	binary.BigEndian.PutUint32(data[4:], 2<<8)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r := openTestRegion(t, path)
+
+	count := 0
+	if err := r.ForEachChunk(func(pos ChunkPos, f *nbt.File) error {
+		count++
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("visited %d chunks, want 1", count)
+	}
+}
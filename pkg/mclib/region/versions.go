@@ -0,0 +1,60 @@
+package region
+
+import (
+	"fmt"
+
+	"github.com/sbreitf1/mctool/pkg/mclib/nbt"
+)
+
+// ChunkDataVersions returns the DataVersion of every present chunk. Chunks
+// are only decoded up to the DataVersion tag, and other top-level values are
+// skipped without being parsed. Chunks from before 1.9 do not have a
+// DataVersion and are omitted.
+func (r *Region) ChunkDataVersions() (map[ChunkPos]int32, error) {
+	versions := make(map[ChunkPos]int32)
+	for _, pos := range r.Chunks() {
+		version, ok, err := r.chunkDataVersion(pos)
+		if err != nil {
+			return nil, fmt.Errorf("chunk %d,%d: %w", pos.X, pos.Z, err)
+		}
+		if ok {
+			versions[pos] = version
+		}
+	}
+	return versions, nil
+}
+
+func (r *Region) chunkDataVersion(pos ChunkPos) (int32, bool, error) {
+	data, compression, err := r.readChunkData(pos.X, pos.Z)
+	if err != nil {
+		return 0, false, err
+	}
+	chunkReader, err := decompressChunk(data, compression)
+	if err != nil {
+		return 0, false, err
+	}
+
+	sr := nbt.NewStreamReader(chunkReader)
+	if _, err := sr.Next(); err != nil {
+		return 0, false, err
+	}
+	for {
+		ev, err := sr.Next()
+		if err != nil {
+			return 0, false, err
+		}
+
+		switch ev.Kind {
+		case nbt.EventEnd:
+			return 0, false, nil
+		case nbt.EventStartCompound, nbt.EventStartList:
+			if err := sr.Skip(); err != nil {
+				return 0, false, err
+			}
+		case nbt.EventValue:
+			if version, ok := nbt.Int(ev.Value); ok && ev.Name == "DataVersion" {
+				return version, true, nil
+			}
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package region
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestChunkDataVersions(t *testing.T) {
+	r := openTestRegion(t, writeTestRegion(t, map[ChunkPos]string{
+		// DataVersion follows values that are skipped
+		{X: 0, Z: 0}: `{Level:{xPos:0,Sections:[{Y:0b}]},Tags:[1,2],DataVersion:1343}`,
+		{X: 1, Z: 0}: `{DataVersion:3465,sections:[]}`,
+		// before 1.9
+		{X: 2, Z: 0}: `{Level:{xPos:2}}`,
+	}))
+
+	versions, err := r.ChunkDataVersions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[ChunkPos]int32{{X: 0, Z: 0}: 1343, {X: 1, Z: 0}: 3465}
+	if !maps.Equal(versions, want) {
+		t.Errorf("ChunkDataVersions() = %v, want %v", versions, want)
+	}
+}
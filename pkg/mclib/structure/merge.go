@@ -0,0 +1,120 @@
+package structure
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// stateKey identifies equal block states independent of property order.
+func (s BlockState) stateKey() string {
+	var sb strings.Builder
+	sb.WriteString(s.Name)
+	for _, key := range slices.Sorted(maps.Keys(s.Properties)) {
+		sb.WriteString("," + key + "=" + s.Properties[key])
+	}
+	return sb.String()
+}
+
+func (s *Structure) validate() error {
+	for i, block := range s.Blocks {
+		if block.State < 0 || block.State >= len(s.Palette) {
+			return fmt.Errorf("block %d: state %d out of palette range", i, block.State)
+		}
+		if block.Pos.X < 0 || block.Pos.Y < 0 || block.Pos.Z < 0 ||
+			block.Pos.X >= s.Size.X || block.Pos.Y >= s.Size.Y || block.Pos.Z >= s.Size.Z {
+			return fmt.Errorf("block %d: position %v outside of size %v", i, block.Pos, s.Size)
+		}
+	}
+	return nil
+}
+
+// clone returns a copy of the state that does not share its properties.
+func (s BlockState) clone() BlockState {
+	return BlockState{Name: s.Name, Properties: maps.Clone(s.Properties)}
+}
+
+// MergeStructures returns a new structure containing the blocks of base and
+// the blocks of add moved by offset, which replace blocks of base at the same
+// position. Palette indices of add are remapped onto the merged palette and
+// the size grows to fit both structures. A negative offset places add before
+// the origin of base, so the merged origin moves and all contents of base are
+// shifted by the inverse amount. Entities of both structures are concatenated.
+// The palette is copied, while NBT data of blocks and entities is shared with
+// the input structures.
+func MergeStructures(base, add *Structure, offset BlockPos) (*Structure, error) {
+	if err := base.validate(); err != nil {
+		return nil, fmt.Errorf("base structure: %w", err)
+	}
+	if err := add.validate(); err != nil {
+		return nil, fmt.Errorf("added structure: %w", err)
+	}
+
+	baseShift := BlockPos{X: max(-offset.X, 0), Y: max(-offset.Y, 0), Z: max(-offset.Z, 0)}
+	addShift := offset.Add(baseShift)
+	merged := &Structure{
+		DataVersion: base.DataVersion,
+		Size: BlockPos{
+			X: max(base.Size.X+baseShift.X, add.Size.X+addShift.X),
+			Y: max(base.Size.Y+baseShift.Y, add.Size.Y+addShift.Y),
+			Z: max(base.Size.Z+baseShift.Z, add.Size.Z+addShift.Z),
+		},
+		Palette:  make([]BlockState, 0, len(base.Palette)+len(add.Palette)),
+		Blocks:   make([]Block, 0, len(base.Blocks)+len(add.Blocks)),
+		Entities: make([]Entity, 0, len(base.Entities)+len(add.Entities)),
+	}
+	for _, state := range base.Palette {
+		merged.Palette = append(merged.Palette, state.clone())
+	}
+
+	stateIndices := make(map[string]int, len(merged.Palette))
+	for i, state := range merged.Palette {
+		if _, exists := stateIndices[state.stateKey()]; !exists {
+			stateIndices[state.stateKey()] = i
+		}
+	}
+	remap := make([]int, len(add.Palette))
+	for i, state := range add.Palette {
+		index, exists := stateIndices[state.stateKey()]
+		if !exists {
+			index = len(merged.Palette)
+			merged.Palette = append(merged.Palette, state.clone())
+			stateIndices[state.stateKey()] = index
+		}
+		remap[i] = index
+	}
+
+	blockIndices := make(map[BlockPos]int, len(base.Blocks)+len(add.Blocks))
+	for _, block := range base.Blocks {
+		block.Pos = block.Pos.Add(baseShift)
+		blockIndices[block.Pos] = len(merged.Blocks)
+		merged.Blocks = append(merged.Blocks, block)
+	}
+	for _, block := range add.Blocks {
+		block.Pos = block.Pos.Add(addShift)
+		block.State = remap[block.State]
+		if i, exists := blockIndices[block.Pos]; exists {
+			merged.Blocks[i] = block
+		} else {
+			blockIndices[block.Pos] = len(merged.Blocks)
+			merged.Blocks = append(merged.Blocks, block)
+		}
+	}
+
+	for _, entity := range base.Entities {
+		merged.Entities = append(merged.Entities, entity.moved(baseShift))
+	}
+	for _, entity := range add.Entities {
+		merged.Entities = append(merged.Entities, entity.moved(addShift))
+	}
+	return merged, nil
+}
+
+func (e Entity) moved(offset BlockPos) Entity {
+	e.Pos[0] += float64(offset.X)
+	e.Pos[1] += float64(offset.Y)
+	e.Pos[2] += float64(offset.Z)
+	e.BlockPos = e.BlockPos.Add(offset)
+	return e
+}
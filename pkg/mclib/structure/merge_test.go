@@ -0,0 +1,86 @@
+package structure
+
+import (
+	"reflect"
+	"testing"
+)
+
+func testStructure(size BlockPos, palette []BlockState, blocks ...Block) *Structure {
+	return &Structure{DataVersion: 3953, Size: size, Palette: palette, Blocks: blocks}
+}
+
+func TestMergeStructures(t *testing.T) {
+	stone := BlockState{Name: "minecraft:stone"}
+	stairs := BlockState{Name: "minecraft:oak_stairs", Properties: map[string]string{"facing": "north"}}
+	base := testStructure(BlockPos{2, 1, 1}, []BlockState{stone},
+		Block{Pos: BlockPos{0, 0, 0}, State: 0},
+		Block{Pos: BlockPos{1, 0, 0}, State: 0},
+	)
+	add := testStructure(BlockPos{2, 1, 1}, []BlockState{stairs, stone},
+		Block{Pos: BlockPos{0, 0, 0}, State: 0},
+		Block{Pos: BlockPos{1, 0, 0}, State: 1},
+	)
+
+	merged, err := MergeStructures(base, add, BlockPos{1, 0, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged.Size != (BlockPos{3, 1, 1}) {
+		t.Errorf("size = %v, want {3 1 1}", merged.Size)
+	}
+	wantPalette := []BlockState{stone, stairs}
+	if !reflect.DeepEqual(merged.Palette, wantPalette) {
+		t.Errorf("palette = %v, want %v", merged.Palette, wantPalette)
+	}
+	wantBlocks := []Block{
+		{Pos: BlockPos{0, 0, 0}, State: 0},
+		{Pos: BlockPos{1, 0, 0}, State: 1},
+		{Pos: BlockPos{2, 0, 0}, State: 0},
+	}
+	if !reflect.DeepEqual(merged.Blocks, wantBlocks) {
+		t.Errorf("blocks = %v, want %v", merged.Blocks, wantBlocks)
+	}
+
+	merged.Palette[1].Properties["facing"] = "south"
+	if stairs.Properties["facing"] != "north" {
+		t.Errorf("merged palette shares properties with the input")
+	}
+}
+
+func TestMergeStructuresNegativeOffset(t *testing.T) {
+	stone := BlockState{Name: "minecraft:stone"}
+	dirt := BlockState{Name: "minecraft:dirt"}
+	base := testStructure(BlockPos{1, 1, 1}, []BlockState{stone}, Block{Pos: BlockPos{0, 0, 0}, State: 0})
+	base.Entities = []Entity{{Pos: [3]float64{0.5, 0, 0.5}, BlockPos: BlockPos{0, 0, 0}}}
+	add := testStructure(BlockPos{1, 1, 1}, []BlockState{dirt}, Block{Pos: BlockPos{0, 0, 0}, State: 0})
+
+	merged, err := MergeStructures(base, add, BlockPos{-2, 0, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged.Size != (BlockPos{3, 1, 1}) {
+		t.Errorf("size = %v, want {3 1 1}", merged.Size)
+	}
+	wantBlocks := []Block{
+		{Pos: BlockPos{2, 0, 0}, State: 0},
+		{Pos: BlockPos{0, 0, 0}, State: 1},
+	}
+	if !reflect.DeepEqual(merged.Blocks, wantBlocks) {
+		t.Errorf("blocks = %v, want %v", merged.Blocks, wantBlocks)
+	}
+	if merged.Entities[0].Pos != [3]float64{2.5, 0, 0.5} || merged.Entities[0].BlockPos != (BlockPos{2, 0, 0}) {
+		t.Errorf("entity of base not shifted: %+v", merged.Entities[0])
+	}
+	if base.Blocks[0].Pos != (BlockPos{0, 0, 0}) || base.Entities[0].Pos[0] != 0.5 {
+		t.Errorf("base structure was modified")
+	}
+}
+
+func TestMergeStructuresInvalidState(t *testing.T) {
+	stone := BlockState{Name: "minecraft:stone"}
+	base := testStructure(BlockPos{1, 1, 1}, []BlockState{stone})
+	add := testStructure(BlockPos{1, 1, 1}, []BlockState{stone}, Block{Pos: BlockPos{0, 0, 0}, State: 1})
+	if _, err := MergeStructures(base, add, BlockPos{}); err == nil {
+		t.Errorf("expected error for out-of-range palette index")
+	}
+}
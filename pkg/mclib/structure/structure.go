@@ -0,0 +1,269 @@
+package structure
+
+import (
+	"fmt"
+
+	"github.com/sbreitf1/mctool/pkg/mclib/nbt"
+)
+
+type BlockPos struct {
+	X, Y, Z int
+}
+
+func (p BlockPos) Add(other BlockPos) BlockPos {
+	return BlockPos{X: p.X + other.X, Y: p.Y + other.Y, Z: p.Z + other.Z}
+}
+
+type BlockState struct {
+	Name       string
+	Properties map[string]string
+}
+
+type Block struct {
+	Pos BlockPos
+	// State is the index of the block state in the palette.
+	State int
+	NBT   *nbt.CompoundNode
+}
+
+type Entity struct {
+	Pos      [3]float64
+	BlockPos BlockPos
+	NBT      *nbt.CompoundNode
+}
+
+// Structure is the content of a structure file as saved by structure blocks.
+type Structure struct {
+	DataVersion int32
+	Size        BlockPos
+	Palette     []BlockState
+	Blocks      []Block
+	Entities    []Entity
+}
+
+// Bounds returns the smallest box containing all blocks of the structure,
+// which can be smaller than Size for sparse structures. It returns false if
+// the structure has no blocks.
+func (s *Structure) Bounds() (BlockPos, BlockPos, bool) {
+	if len(s.Blocks) == 0 {
+		return BlockPos{}, BlockPos{}, false
+	}
+
+	minPos, maxPos := s.Blocks[0].Pos, s.Blocks[0].Pos
+	for _, block := range s.Blocks[1:] {
+		minPos.X, minPos.Y, minPos.Z = min(minPos.X, block.Pos.X), min(minPos.Y, block.Pos.Y), min(minPos.Z, block.Pos.Z)
+		maxPos.X, maxPos.Y, maxPos.Z = max(maxPos.X, block.Pos.X), max(maxPos.Y, block.Pos.Y), max(maxPos.Z, block.Pos.Z)
+	}
+	return minPos, maxPos, true
+}
+
+// Parse reads a structure file. For structures with several alternative
+// palettes, only the first one is used.
+func Parse(f *nbt.File) (*Structure, error) {
+	rootNode := f.Query("").Nodes()
+	if len(rootNode) != 1 {
+		return nil, fmt.Errorf("missing top-level compound")
+	}
+	root, ok := rootNode[0].(*nbt.CompoundNode)
+	if !ok {
+		return nil, fmt.Errorf("missing top-level compound")
+	}
+
+	s := &Structure{}
+	s.DataVersion, _ = nbt.Int(root.Values["DataVersion"])
+
+	var err error
+	if s.Size, err = parseBlockPos(root.Values["size"]); err != nil {
+		return nil, fmt.Errorf("size: %w", err)
+	}
+
+	paletteNode, ok := root.Values["palette"].(*nbt.ListNode)
+	if !ok {
+		if palettes, ok := root.Values["palettes"].(*nbt.ListNode); ok && len(palettes.Values) > 0 {
+			paletteNode, _ = palettes.Values[0].(*nbt.ListNode)
+		}
+	}
+	if paletteNode == nil {
+		return nil, fmt.Errorf("missing palette")
+	}
+	for i, node := range paletteNode.Values {
+		state, err := parseBlockState(node)
+		if err != nil {
+			return nil, fmt.Errorf("palette index %d: %w", i, err)
+		}
+		s.Palette = append(s.Palette, state)
+	}
+
+	for i, node := range compoundList(root.Values["blocks"]) {
+		block, err := parseBlock(node)
+		if err != nil {
+			return nil, fmt.Errorf("block %d: %w", i, err)
+		}
+		if block.State < 0 || block.State >= len(s.Palette) {
+			return nil, fmt.Errorf("block %d: state %d out of palette range", i, block.State)
+		}
+		s.Blocks = append(s.Blocks, block)
+	}
+
+	for i, node := range compoundList(root.Values["entities"]) {
+		entity, err := parseEntity(node)
+		if err != nil {
+			return nil, fmt.Errorf("entity %d: %w", i, err)
+		}
+		s.Entities = append(s.Entities, entity)
+	}
+	return s, nil
+}
+
+func compoundList(node nbt.Node) []*nbt.CompoundNode {
+	list, ok := node.(*nbt.ListNode)
+	if !ok {
+		return nil
+	}
+	compounds := make([]*nbt.CompoundNode, 0, len(list.Values))
+	for _, childNode := range list.Values {
+		if compound, ok := childNode.(*nbt.CompoundNode); ok {
+			compounds = append(compounds, compound)
+		}
+	}
+	return compounds
+}
+
+func parseBlockPos(node nbt.Node) (BlockPos, error) {
+	list, ok := node.(*nbt.ListNode)
+	if !ok || len(list.Values) != 3 {
+		return BlockPos{}, fmt.Errorf("expected list of 3 ints")
+	}
+	var coords [3]int
+	for i, childNode := range list.Values {
+		val, ok := nbt.Int(childNode)
+		if !ok {
+			return BlockPos{}, fmt.Errorf("expected list of 3 ints")
+		}
+		coords[i] = int(val)
+	}
+	return BlockPos{X: coords[0], Y: coords[1], Z: coords[2]}, nil
+}
+
+func parseBlockState(node nbt.Node) (BlockState, error) {
+	compound, ok := node.(*nbt.CompoundNode)
+	if !ok {
+		return BlockState{}, fmt.Errorf("expected compound, got %T", node)
+	}
+	name, ok := nbt.Str(compound.Values["Name"])
+	if !ok {
+		return BlockState{}, fmt.Errorf("missing Name")
+	}
+
+	state := BlockState{Name: name}
+	if properties, ok := compound.Values["Properties"].(*nbt.CompoundNode); ok {
+		state.Properties = make(map[string]string, len(properties.Values))
+		for key, val := range properties.Values {
+			if str, ok := nbt.Str(val); ok {
+				state.Properties[key] = str
+			}
+		}
+	}
+	return state, nil
+}
+
+func parseBlock(node *nbt.CompoundNode) (Block, error) {
+	pos, err := parseBlockPos(node.Values["pos"])
+	if err != nil {
+		return Block{}, fmt.Errorf("pos: %w", err)
+	}
+	state, ok := nbt.Int(node.Values["state"])
+	if !ok {
+		return Block{}, fmt.Errorf("missing state")
+	}
+
+	block := Block{Pos: pos, State: int(state)}
+	block.NBT, _ = node.Values["nbt"].(*nbt.CompoundNode)
+	return block, nil
+}
+
+func parseEntity(node *nbt.CompoundNode) (Entity, error) {
+	entity := Entity{}
+	pos, ok := node.Values["pos"].(*nbt.ListNode)
+	if !ok || len(pos.Values) != 3 {
+		return Entity{}, fmt.Errorf("pos: expected list of 3 doubles")
+	}
+	for i, childNode := range pos.Values {
+		if entity.Pos[i], ok = nbt.F64(childNode); !ok {
+			return Entity{}, fmt.Errorf("pos: expected list of 3 doubles")
+		}
+	}
+
+	var err error
+	if entity.BlockPos, err = parseBlockPos(node.Values["blockPos"]); err != nil {
+		return Entity{}, fmt.Errorf("blockPos: %w", err)
+	}
+	entity.NBT, _ = node.Values["nbt"].(*nbt.CompoundNode)
+	return entity, nil
+}
+
+// ToFile converts the structure back into its NBT representation. Block and
+// entity NBT data is shared with the structure.
+func (s *Structure) ToFile() *nbt.File {
+	palette := &nbt.ListNode{ElementType: nbt.NodeTypeCompound, Values: make([]nbt.Node, 0, len(s.Palette))}
+	for _, state := range s.Palette {
+		stateNode := &nbt.CompoundNode{Values: map[string]nbt.Node{
+			"Name": &nbt.StringNode{Value: state.Name},
+		}}
+		if len(state.Properties) > 0 {
+			properties := &nbt.CompoundNode{Values: make(map[string]nbt.Node, len(state.Properties))}
+			for key, val := range state.Properties {
+				properties.Values[key] = &nbt.StringNode{Value: val}
+			}
+			stateNode.Values["Properties"] = properties
+		}
+		palette.Values = append(palette.Values, stateNode)
+	}
+
+	blocks := &nbt.ListNode{ElementType: nbt.NodeTypeCompound, Values: make([]nbt.Node, 0, len(s.Blocks))}
+	for _, block := range s.Blocks {
+		blockNode := &nbt.CompoundNode{Values: map[string]nbt.Node{
+			"pos":   blockPosNode(block.Pos),
+			"state": &nbt.IntNode{Value: int32(block.State)},
+		}}
+		if block.NBT != nil {
+			blockNode.Values["nbt"] = block.NBT
+		}
+		blocks.Values = append(blocks.Values, blockNode)
+	}
+
+	entities := &nbt.ListNode{ElementType: nbt.NodeTypeCompound, Values: make([]nbt.Node, 0, len(s.Entities))}
+	for _, entity := range s.Entities {
+		entityNode := &nbt.CompoundNode{Values: map[string]nbt.Node{
+			"pos": &nbt.ListNode{Values: []nbt.Node{
+				&nbt.DoubleNode{Value: entity.Pos[0]},
+				&nbt.DoubleNode{Value: entity.Pos[1]},
+				&nbt.DoubleNode{Value: entity.Pos[2]},
+			}},
+			"blockPos": blockPosNode(entity.BlockPos),
+		}}
+		if entity.NBT != nil {
+			entityNode.Values["nbt"] = entity.NBT
+		}
+		entities.Values = append(entities.Values, entityNode)
+	}
+
+	root := &nbt.CompoundNode{Values: map[string]nbt.Node{
+		"DataVersion": &nbt.IntNode{Value: s.DataVersion},
+		"size":        blockPosNode(s.Size),
+		"palette":     palette,
+		"blocks":      blocks,
+		"entities":    entities,
+	}}
+	return &nbt.File{
+		Root: root,
+	}
+}
+
+func blockPosNode(pos BlockPos) *nbt.ListNode {
+	return &nbt.ListNode{Values: []nbt.Node{
+		&nbt.IntNode{Value: int32(pos.X)},
+		&nbt.IntNode{Value: int32(pos.Y)},
+		&nbt.IntNode{Value: int32(pos.Z)},
+	}}
+}
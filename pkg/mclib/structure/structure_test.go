@@ -0,0 +1,33 @@
+package structure
+
+import (
+	"testing"
+
+	"github.com/sbreitf1/mctool/pkg/mclib/nbt"
+)
+
+func TestBounds(t *testing.T) {
+	// a 16x16x16 structure with only three blocks
+	f, err := nbt.ParseSNBT(`{DataVersion:3953,size:[16,16,16],palette:[{Name:"minecraft:stone"},{Name:"minecraft:dirt"}],` +
+		`blocks:[{pos:[3,1,7],state:0},{pos:[5,4,2],state:1},{pos:[4,2,9],state:0}]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	minPos, maxPos, ok := s.Bounds()
+	if !ok {
+		t.Fatal("no bounds for structure with blocks")
+	}
+	if minPos != (BlockPos{3, 1, 2}) || maxPos != (BlockPos{5, 4, 9}) {
+		t.Errorf("bounds = %v to %v, want {3 1 2} to {5 4 9}", minPos, maxPos)
+	}
+
+	s.Blocks = nil
+	if minPos, maxPos, ok := s.Bounds(); ok || minPos != (BlockPos{}) || maxPos != (BlockPos{}) {
+		t.Errorf("bounds of empty structure = %v to %v, %v", minPos, maxPos, ok)
+	}
+}
@@ -0,0 +1,151 @@
+package structure
+
+import (
+	"fmt"
+	"strconv"
+)
+
+type Axis int
+
+const (
+	// AxisX mirrors the structure along the x axis, swapping east and west.
+	AxisX Axis = iota
+	// AxisZ mirrors the structure along the z axis, swapping north and south.
+	AxisZ
+)
+
+// Rotate turns the structure clockwise around the y axis as seen from above.
+// degrees must be a multiple of 90. Block positions and entity positions are
+// moved to stay within the rotated size, and the facing, axis, rotation and
+// north/east/south/west properties of the palette are rotated accordingly.
+// Other properties, e.g. rail shapes, and block and entity NBT data are kept
+// unchanged.
+func (s *Structure) Rotate(degrees int) error {
+	if degrees%90 != 0 {
+		return fmt.Errorf("rotation of %d degrees is not a multiple of 90", degrees)
+	}
+	if err := s.validate(); err != nil {
+		return err
+	}
+
+	quarters := ((degrees/90)%4 + 4) % 4
+	for range quarters {
+		s.rotateClockwise()
+	}
+	return nil
+}
+
+func (s *Structure) rotateClockwise() {
+	// north (-z) becomes east (+x), so x' = sizeZ-1-z and z' = x
+	for i := range s.Blocks {
+		pos := s.Blocks[i].Pos
+		s.Blocks[i].Pos = BlockPos{X: s.Size.Z - 1 - pos.Z, Y: pos.Y, Z: pos.X}
+	}
+	for i := range s.Entities {
+		entity := &s.Entities[i]
+		entity.Pos[0], entity.Pos[2] = float64(s.Size.Z)-entity.Pos[2], entity.Pos[0]
+		pos := entity.BlockPos
+		entity.BlockPos = BlockPos{X: s.Size.Z - 1 - pos.Z, Y: pos.Y, Z: pos.X}
+	}
+	s.Size.X, s.Size.Z = s.Size.Z, s.Size.X
+
+	for i := range s.Palette {
+		s.Palette[i] = s.Palette[i].transform(rotatedDirections, func(rotation int) int { return rotation + 4 })
+		if axis, ok := s.Palette[i].Properties["axis"]; ok {
+			switch axis {
+			case "x":
+				s.Palette[i].Properties["axis"] = "z"
+			case "z":
+				s.Palette[i].Properties["axis"] = "x"
+			}
+		}
+	}
+}
+
+// Mirror flips the structure along the given axis. Block positions and entity
+// positions are moved to stay within the size, and the facing, rotation and
+// north/east/south/west properties of the palette are mirrored accordingly.
+// Mirroring swaps left and right, so the inner and outer shapes of stairs are
+// swapped as well. Other properties, e.g. rail shapes, and block and entity
+// NBT data are kept unchanged.
+func (s *Structure) Mirror(axis Axis) error {
+	if axis != AxisX && axis != AxisZ {
+		return fmt.Errorf("unsupported mirror axis %d", axis)
+	}
+	if err := s.validate(); err != nil {
+		return err
+	}
+
+	for i := range s.Blocks {
+		pos := &s.Blocks[i].Pos
+		if axis == AxisX {
+			pos.X = s.Size.X - 1 - pos.X
+		} else {
+			pos.Z = s.Size.Z - 1 - pos.Z
+		}
+	}
+	for i := range s.Entities {
+		entity := &s.Entities[i]
+		if axis == AxisX {
+			entity.Pos[0] = float64(s.Size.X) - entity.Pos[0]
+			entity.BlockPos.X = s.Size.X - 1 - entity.BlockPos.X
+		} else {
+			entity.Pos[2] = float64(s.Size.Z) - entity.Pos[2]
+			entity.BlockPos.Z = s.Size.Z - 1 - entity.BlockPos.Z
+		}
+	}
+
+	// rotation counts sixteenths of a turn clockwise, starting at south
+	directions, mirrorRotation := mirroredXDirections, func(rotation int) int { return 16 - rotation }
+	if axis == AxisZ {
+		directions, mirrorRotation = mirroredZDirections, func(rotation int) int { return 8 - rotation }
+	}
+	for i := range s.Palette {
+		s.Palette[i] = s.Palette[i].transform(directions, mirrorRotation)
+		if shape, ok := mirroredStairShapes[s.Palette[i].Properties["shape"]]; ok {
+			s.Palette[i].Properties["shape"] = shape
+		}
+	}
+	return nil
+}
+
+var (
+	rotatedDirections   = map[string]string{"north": "east", "east": "south", "south": "west", "west": "north"}
+	mirroredXDirections = map[string]string{"east": "west", "west": "east"}
+	mirroredZDirections = map[string]string{"north": "south", "south": "north"}
+	mirroredStairShapes = map[string]string{
+		"inner_left": "inner_right", "inner_right": "inner_left",
+		"outer_left": "outer_right", "outer_right": "outer_left",
+	}
+)
+
+// transform returns a copy of the block state with facing values and
+// north/east/south/west properties mapped by directions and the rotation
+// property of signs, banners and skulls mapped by rotation.
+func (s BlockState) transform(directions map[string]string, rotation func(int) int) BlockState {
+	if len(s.Properties) == 0 {
+		return s
+	}
+
+	// directions is a permutation, so renamed keys never collide
+	properties := make(map[string]string, len(s.Properties))
+	for key, val := range s.Properties {
+		switch key {
+		case "facing":
+			if newVal, ok := directions[val]; ok {
+				val = newVal
+			}
+		case "rotation":
+			if num, err := strconv.Atoi(val); err == nil {
+				val = strconv.Itoa(((rotation(num) % 16) + 16) % 16)
+			}
+		}
+		// connections of fences, walls and redstone are keyed by direction
+		if newKey, ok := directions[key]; ok {
+			key = newKey
+		}
+		properties[key] = val
+	}
+	s.Properties = properties
+	return s
+}
@@ -0,0 +1,106 @@
+package structure
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRotate90(t *testing.T) {
+	s := testStructure(BlockPos{2, 1, 3},
+		[]BlockState{
+			{Name: "minecraft:oak_stairs", Properties: map[string]string{"facing": "north", "shape": "straight"}},
+			{Name: "minecraft:oak_log", Properties: map[string]string{"axis": "x"}},
+			{Name: "minecraft:oak_fence", Properties: map[string]string{"north": "true", "east": "false"}},
+		},
+		Block{Pos: BlockPos{0, 0, 0}, State: 0},
+		Block{Pos: BlockPos{1, 0, 2}, State: 1},
+		Block{Pos: BlockPos{1, 0, 0}, State: 2},
+	)
+	s.Entities = []Entity{{Pos: [3]float64{0.5, 0, 2.5}, BlockPos: BlockPos{0, 0, 2}}}
+
+	if err := s.Rotate(90); err != nil {
+		t.Fatal(err)
+	}
+	if s.Size != (BlockPos{3, 1, 2}) {
+		t.Errorf("size = %v, want {3 1 2}", s.Size)
+	}
+	wantPos := []BlockPos{{2, 0, 0}, {0, 0, 1}, {2, 0, 1}}
+	for i, block := range s.Blocks {
+		if block.Pos != wantPos[i] {
+			t.Errorf("block %d at %v, want %v", i, block.Pos, wantPos[i])
+		}
+	}
+	if facing := s.Palette[0].Properties["facing"]; facing != "east" {
+		t.Errorf("facing = %q, want east", facing)
+	}
+	if axis := s.Palette[1].Properties["axis"]; axis != "z" {
+		t.Errorf("axis = %q, want z", axis)
+	}
+	wantFence := map[string]string{"east": "true", "south": "false"}
+	if !reflect.DeepEqual(s.Palette[2].Properties, wantFence) {
+		t.Errorf("fence properties = %v, want %v", s.Palette[2].Properties, wantFence)
+	}
+	if s.Entities[0].Pos != [3]float64{0.5, 0, 0.5} || s.Entities[0].BlockPos != (BlockPos{0, 0, 0}) {
+		t.Errorf("entity = %+v", s.Entities[0])
+	}
+
+	if err := s.Rotate(270); err != nil {
+		t.Fatal(err)
+	}
+	if s.Blocks[0].Pos != (BlockPos{0, 0, 0}) || s.Palette[0].Properties["facing"] != "north" {
+		t.Errorf("full turn did not restore the structure: %v %v", s.Blocks[0], s.Palette[0])
+	}
+
+	if err := s.Rotate(45); err == nil {
+		t.Errorf("expected error for rotation by 45 degrees")
+	}
+}
+
+func TestMirrorStairs(t *testing.T) {
+	s := testStructure(BlockPos{3, 1, 1},
+		[]BlockState{
+			{Name: "minecraft:oak_stairs", Properties: map[string]string{"facing": "east", "shape": "outer_left"}},
+			{Name: "minecraft:oak_stairs", Properties: map[string]string{"facing": "north", "shape": "inner_right"}},
+			{Name: "minecraft:rail", Properties: map[string]string{"shape": "north_south"}},
+		},
+		Block{Pos: BlockPos{0, 0, 0}, State: 0},
+		Block{Pos: BlockPos{1, 0, 0}, State: 1},
+		Block{Pos: BlockPos{2, 0, 0}, State: 2},
+	)
+	if err := s.Mirror(AxisX); err != nil {
+		t.Fatal(err)
+	}
+	if s.Blocks[0].Pos != (BlockPos{2, 0, 0}) {
+		t.Errorf("block at %v, want {2 0 0}", s.Blocks[0].Pos)
+	}
+	want := []map[string]string{
+		{"facing": "west", "shape": "outer_right"},
+		{"facing": "north", "shape": "inner_left"},
+		{"shape": "north_south"},
+	}
+	for i, state := range s.Palette {
+		if !reflect.DeepEqual(state.Properties, want[i]) {
+			t.Errorf("palette %d = %v, want %v", i, state.Properties, want[i])
+		}
+	}
+}
+
+func TestMirrorRotation(t *testing.T) {
+	s := testStructure(BlockPos{1, 1, 1},
+		[]BlockState{{Name: "minecraft:oak_sign", Properties: map[string]string{"rotation": "4"}}},
+		Block{Pos: BlockPos{0, 0, 0}, State: 0},
+	)
+	if err := s.Mirror(AxisZ); err != nil {
+		t.Fatal(err)
+	}
+	// west (4) stays west when swapping north and south
+	if rotation := s.Palette[0].Properties["rotation"]; rotation != "4" {
+		t.Errorf("rotation = %q, want 4", rotation)
+	}
+	if err := s.Mirror(AxisX); err != nil {
+		t.Fatal(err)
+	}
+	if rotation := s.Palette[0].Properties["rotation"]; rotation != "12" {
+		t.Errorf("rotation = %q, want 12", rotation)
+	}
+}
@@ -0,0 +1,158 @@
+package world
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/sbreitf1/mctool/pkg/mclib/nbt"
+	"github.com/sbreitf1/mctool/pkg/mclib/region"
+)
+
+// minDuplicateStacks is the number of stacks with identical custom data from
+// which an item is reported as duplicate.
+const minDuplicateStacks = 2
+
+var containerPaths = []string{
+	// since 1.18
+	"block_entities[*]",
+	// before 1.18
+	"Level.TileEntities[*]",
+}
+
+// ItemLocation describes where an item stack is stored.
+type ItemLocation struct {
+	// Holder is the id of the container block entity, or "player:<uuid>"
+	// for player inventories.
+	Holder string
+	// Pos is the block position of a container. It is zero for players.
+	Pos  [3]int32
+	Slot int8
+}
+
+type DupeReport struct {
+	Hash      [sha256.Size]byte
+	ID        string
+	Item      *nbt.CompoundNode
+	Locations []ItemLocation
+}
+
+// FindDuplicateItems scans all container block entities and player
+// inventories for item stacks that are equal apart from their slot.
+//
+// Plain stacks are common by nature, so only items carrying custom data
+// ("tag" or "components", e.g. names or enchantments) are considered. Such
+// an item is reported once it appears in at least two stacks. Items nested
+// in other items, e.g. inside shulker boxes, are not inspected.
+func (w *World) FindDuplicateItems() ([]DupeReport, error) {
+	reports := make(map[[sha256.Size]byte]*DupeReport)
+	collect := func(items []nbt.Node, holder string, pos [3]int32) error {
+		for _, node := range items {
+			if err := collectDupeCandidate(reports, node, holder, pos); err != nil {
+				return fmt.Errorf("%s at %v: %w", holder, pos, err)
+			}
+		}
+		return nil
+	}
+
+	if err := w.forEachChunk("region", func(_ region.ChunkPos, chunk *nbt.File) error {
+		for _, path := range containerPaths {
+			sel := chunk.Query(path)
+			if err := sel.Err(); err != nil {
+				return err
+			}
+			for _, node := range sel.Nodes() {
+				container, ok := node.(*nbt.CompoundNode)
+				if !ok {
+					continue
+				}
+				items, ok := container.Values["Items"].(*nbt.ListNode)
+				if !ok {
+					continue
+				}
+				id, _ := nbt.Str(container.Values["id"])
+				if err := collect(items.Values, id, blockEntityPos(container)); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	players, err := filepath.Glob(filepath.Join(w.Dir, "playerdata", "*.dat"))
+	if err != nil {
+		return nil, fmt.Errorf("list player data: %w", err)
+	}
+	for _, file := range players {
+		player, err := nbt.ReadFromFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("read player data %q: %w", filepath.Base(file), err)
+		}
+		holder := "player:" + strings.TrimSuffix(filepath.Base(file), ".dat")
+		for _, key := range []string{"Inventory", "EnderItems"} {
+			if err := collect(player.Query(key+"[*]").Nodes(), holder, [3]int32{}); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	result := make([]DupeReport, 0)
+	for _, report := range reports {
+		if len(report.Locations) >= minDuplicateStacks {
+			result = append(result, *report)
+		}
+	}
+	slices.SortFunc(result, func(a, b DupeReport) int {
+		if c := strings.Compare(a.ID, b.ID); c != 0 {
+			return c
+		}
+		return slices.Compare(a.Hash[:], b.Hash[:])
+	})
+	return result, nil
+}
+
+func collectDupeCandidate(reports map[[sha256.Size]byte]*DupeReport, node nbt.Node, holder string, pos [3]int32) error {
+	item, ok := node.(*nbt.CompoundNode)
+	if !ok {
+		return nil
+	}
+	_, hasTag := item.Values["tag"]
+	_, hasComponents := item.Values["components"]
+	if !hasTag && !hasComponents {
+		return nil
+	}
+
+	// the slot differs between stacks and is not part of the item itself
+	content := &nbt.CompoundNode{Values: make(map[string]nbt.Node, len(item.Values))}
+	for key, childNode := range item.Values {
+		if key != "Slot" {
+			content.Values[key] = childNode
+		}
+	}
+	hash, err := nbt.ContentHash(content)
+	if err != nil {
+		return err
+	}
+
+	report, ok := reports[hash]
+	if !ok {
+		id, _ := nbt.Str(item.Values["id"])
+		report = &DupeReport{Hash: hash, ID: id, Item: item}
+		reports[hash] = report
+	}
+	slot, _ := nbt.Byte(item.Values["Slot"])
+	report.Locations = append(report.Locations, ItemLocation{Holder: holder, Pos: pos, Slot: int8(slot)})
+	return nil
+}
+
+func blockEntityPos(n *nbt.CompoundNode) [3]int32 {
+	var pos [3]int32
+	for i, key := range []string{"x", "y", "z"} {
+		pos[i], _ = nbt.Int(n.Values[key])
+	}
+	return pos
+}
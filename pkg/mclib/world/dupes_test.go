@@ -0,0 +1,56 @@
+package world
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/sbreitf1/mctool/pkg/mclib/nbt"
+	"github.com/sbreitf1/mctool/pkg/mclib/region"
+)
+
+func TestFindDuplicateItems(t *testing.T) {
+	const sword = `id:"minecraft:diamond_sword",Count:1b,tag:{display:{Name:'"Duped"'},Damage:0}`
+	dir := t.TempDir()
+	writeTestRegion(t, dir, "region", 0, 0, map[region.ChunkPos]string{
+		{X: 0, Z: 0}: `{block_entities:[{id:"minecraft:chest",x:1,y:64,z:2,Items:[` +
+			`{Slot:0b,` + sword + `},` +
+			`{Slot:1b,id:"minecraft:stone",Count:64b},` +
+			`{Slot:2b,id:"minecraft:stone",Count:64b},` +
+			`{Slot:3b,` + sword + `},` +
+			`{Slot:4b,id:"minecraft:bow",Count:1b,tag:{Damage:3}}]}]}`,
+	})
+
+	player, err := nbt.Encode(`{Inventory:[{Slot:8b,`+sword+`}],EnderItems:[]}`, nbt.CompressionGZip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.MkdirAll(filepath.Join(dir, "playerdata"), 0o755)
+	if err := os.WriteFile(filepath.Join(dir, "playerdata", "uuid.dat"), player, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := OpenWorld(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reports, err := w.FindDuplicateItems()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 1 {
+		t.Fatalf("got %d reports, want 1: %+v", len(reports), reports)
+	}
+	if reports[0].ID != "minecraft:diamond_sword" {
+		t.Errorf("reported item %s", reports[0].ID)
+	}
+	want := []ItemLocation{
+		{Holder: "minecraft:chest", Pos: [3]int32{1, 64, 2}, Slot: 0},
+		{Holder: "minecraft:chest", Pos: [3]int32{1, 64, 2}, Slot: 3},
+		{Holder: "player:uuid", Slot: 8},
+	}
+	if !reflect.DeepEqual(reports[0].Locations, want) {
+		t.Errorf("locations = %+v, want %+v", reports[0].Locations, want)
+	}
+}
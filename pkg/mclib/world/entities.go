@@ -0,0 +1,82 @@
+package world
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/sbreitf1/mctool/pkg/mclib/nbt"
+	"github.com/sbreitf1/mctool/pkg/mclib/region"
+)
+
+type Entity struct {
+	ID   string
+	Pos  [3]float64
+	Data *nbt.CompoundNode
+}
+
+// Entities returns all entities stored for the chunk at the absolute chunk
+// coordinates. Worlds since 1.17 keep them in the separate entities folder,
+// older worlds inside the chunk itself.
+func (w *World) Entities(cx, cz int) ([]Entity, error) {
+	entityChunk, err := w.readChunk("entities", cx, cz)
+	if err == nil {
+		return parseEntities(entityChunk.Query("Entities[*]"))
+	}
+	if !errors.Is(err, region.ErrChunkNotPresent) {
+		return nil, fmt.Errorf("read entity chunk: %w", err)
+	}
+
+	chunk, err := w.readChunk("region", cx, cz)
+	if err != nil {
+		return nil, fmt.Errorf("read chunk: %w", err)
+	}
+	return parseEntities(chunk.Query("Level.Entities[*]"))
+}
+
+func parseEntities(sel *nbt.Selection) ([]Entity, error) {
+	if err := sel.Err(); err != nil {
+		return nil, err
+	}
+
+	entities := make([]Entity, 0, sel.Len())
+	for i, node := range sel.Nodes() {
+		entity, err := parseEntity(node)
+		if err != nil {
+			return nil, fmt.Errorf("entity %d: %w", i, err)
+		}
+		entities = append(entities, *entity)
+	}
+	return entities, nil
+}
+
+func parseEntity(node nbt.Node) (*Entity, error) {
+	data, ok := node.(*nbt.CompoundNode)
+	if !ok {
+		return nil, fmt.Errorf("entity must be a compound, got %T", node)
+	}
+
+	entity := &Entity{Data: data}
+	if id, ok := data.Values["id"].(*nbt.StringNode); ok {
+		entity.ID = id.Value
+	}
+	if pos, ok := data.Values["Pos"].(*nbt.ListNode); ok && len(pos.Values) == 3 {
+		for i, coord := range pos.Values {
+			if val, ok := coord.(*nbt.DoubleNode); ok {
+				entity.Pos[i] = val.Value
+			}
+		}
+	}
+	return entity, nil
+}
+
+// readChunk reads a chunk at absolute chunk coordinates from the region
+// files in folder. Missing region files are reported as region.ErrChunkNotPresent.
+func (w *World) readChunk(folder string, cx, cz int) (*nbt.File, error) {
+	r, err := w.openRegion(folder, cx>>5, cz>>5)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return r.ReadChunk(cx&31, cz&31)
+}
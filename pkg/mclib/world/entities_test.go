@@ -0,0 +1,69 @@
+package world
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sbreitf1/mctool/pkg/mclib/region"
+)
+
+func TestEntitiesFolder(t *testing.T) {
+	dir := t.TempDir()
+	writeTestRegion(t, dir, "entities", -1, 0, map[region.ChunkPos]string{
+		{X: 31, Z: 2}: `{DataVersion:3465,Position:[I;-1,2],Entities:[{id:"minecraft:cow",Pos:[-8.5d,64.0d,40.5d]},{id:"minecraft:pig",Pos:[-3.0d,65.0d,33.0d]}]}`,
+	})
+	// the chunk itself must not be consulted when an entity chunk exists
+	writeTestRegion(t, dir, "region", -1, 0, map[region.ChunkPos]string{
+		{X: 31, Z: 2}: `{Level:{Entities:[{id:"minecraft:zombie"}]}}`,
+	})
+
+	w, err := OpenWorld(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entities, err := w.Entities(-1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entities) != 2 {
+		t.Fatalf("got %d entities, want 2", len(entities))
+	}
+	if entities[0].ID != "minecraft:cow" || entities[0].Pos != [3]float64{-8.5, 64, 40.5} {
+		t.Errorf("entity 0 = %s at %v", entities[0].ID, entities[0].Pos)
+	}
+	if entities[1].ID != "minecraft:pig" || entities[1].Data == nil {
+		t.Errorf("entity 1 = %s with data %v", entities[1].ID, entities[1].Data)
+	}
+}
+
+func TestEntitiesInChunk(t *testing.T) {
+	dir := t.TempDir()
+	writeTestRegion(t, dir, "region", 0, 0, map[region.ChunkPos]string{
+		{X: 1, Z: 0}: `{Level:{xPos:1,zPos:0,Entities:[{id:"minecraft:sheep",Pos:[20.5d,70.0d,4.0d]}]}}`,
+		{X: 2, Z: 0}: `{Level:{xPos:2,zPos:0,Entities:[]}}`,
+	})
+
+	w, err := OpenWorld(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entities, err := w.Entities(1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entities) != 1 || entities[0].ID != "minecraft:sheep" || entities[0].Pos[0] != 20.5 {
+		t.Errorf("entities = %+v", entities)
+	}
+
+	entities, err = w.Entities(2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entities) != 0 {
+		t.Errorf("got %d entities in empty chunk", len(entities))
+	}
+
+	if _, err := w.Entities(3, 0); !errors.Is(err, region.ErrChunkNotPresent) {
+		t.Errorf("missing chunk: %v, want ErrChunkNotPresent", err)
+	}
+}
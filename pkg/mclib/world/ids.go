@@ -0,0 +1,60 @@
+package world
+
+import (
+	"github.com/sbreitf1/mctool/pkg/mclib/nbt"
+	"github.com/sbreitf1/mctool/pkg/mclib/region"
+)
+
+var (
+	blockIDPaths = []string{
+		// since 1.18
+		"sections[*].block_states.palette[*].Name",
+		// 1.13 to 1.17
+		"Level.Sections[*].Palette[*].Name",
+	}
+	entityIDPaths = []string{
+		// entities folder since 1.17
+		"Entities[*].id",
+		// before 1.17
+		"Level.Entities[*].id",
+	}
+)
+
+// AllBlockIDs returns every block id found in the chunk palettes. Chunks are
+// parsed one at a time, so memory usage does not grow with the world size.
+// Numeric blocks of worlds before 1.13 are not included.
+func (w *World) AllBlockIDs() (map[string]bool, error) {
+	ids := make(map[string]bool)
+	if err := w.forEachChunk("region", collectIDs(ids, blockIDPaths)); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// AllEntityIDs returns every entity id found in the chunks of the world.
+func (w *World) AllEntityIDs() (map[string]bool, error) {
+	ids := make(map[string]bool)
+	for _, folder := range []string{"entities", "region"} {
+		if err := w.forEachChunk(folder, collectIDs(ids, entityIDPaths)); err != nil {
+			return nil, err
+		}
+	}
+	return ids, nil
+}
+
+func collectIDs(ids map[string]bool, paths []string) func(region.ChunkPos, *nbt.File) error {
+	return func(_ region.ChunkPos, chunk *nbt.File) error {
+		for _, path := range paths {
+			sel := chunk.Query(path)
+			if err := sel.Err(); err != nil {
+				return err
+			}
+			for _, node := range sel.Nodes() {
+				if id, ok := node.(*nbt.StringNode); ok {
+					ids[id.Value] = true
+				}
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,45 @@
+package world
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/sbreitf1/mctool/pkg/mclib/region"
+)
+
+func TestAllIDs(t *testing.T) {
+	dir := t.TempDir()
+	writeTestRegion(t, dir, "region", 0, 0, map[region.ChunkPos]string{
+		{X: 0, Z: 0}: `{sections:[{block_states:{palette:[{Name:"minecraft:air"},{Name:"minecraft:stone"}]}},{block_states:{palette:[{Name:"minecraft:stone"},{Name:"minecraft:dirt"}]}}]}`,
+		{X: 1, Z: 0}: `{Level:{Sections:[{Palette:[{Name:"minecraft:oak_log",Properties:{axis:"y"}}]}],Entities:[{id:"minecraft:cow"}]}}`,
+	})
+	// slots without chunk data are skipped
+	addEmptySlot(t, dir, "region", 0, 0, region.ChunkPos{X: 2, Z: 0})
+	writeTestRegion(t, dir, "entities", 0, 0, map[region.ChunkPos]string{
+		{X: 0, Z: 0}: `{Entities:[{id:"minecraft:pig"},{id:"minecraft:cow"}]}`,
+	})
+
+	w, err := OpenWorld(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blockIDs, err := w.AllBlockIDs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"minecraft:air", "minecraft:dirt", "minecraft:oak_log", "minecraft:stone"}
+	if ids := slices.Sorted(maps.Keys(blockIDs)); !slices.Equal(ids, want) {
+		t.Errorf("AllBlockIDs() = %v, want %v", ids, want)
+	}
+
+	entityIDs, err := w.AllEntityIDs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []string{"minecraft:cow", "minecraft:pig"}
+	if ids := slices.Sorted(maps.Keys(entityIDs)); !slices.Equal(ids, want) {
+		t.Errorf("AllEntityIDs() = %v, want %v", ids, want)
+	}
+}
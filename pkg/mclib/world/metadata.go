@@ -0,0 +1,53 @@
+package world
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sbreitf1/mctool/pkg/mclib/nbt"
+)
+
+type WorldMeta struct {
+	// ModTime is the modification time of the world folder.
+	ModTime time.Time
+	// Locked is set if a session.lock file exists. The game holds it while
+	// the world is open, but it may also be left over after a crash.
+	Locked bool
+	// LastPlayed is read from level.dat and is zero if unknown.
+	LastPlayed time.Time
+}
+
+// WorldMetadata gathers status information about the world in dir without
+// parsing more than level.dat. The session lock is only checked for
+// existence and never acquired.
+func WorldMetadata(dir string) (*WorldMeta, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("stat world folder: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%q is not a directory", dir)
+	}
+	meta := &WorldMeta{
+		ModTime: info.ModTime(),
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "session.lock")); err == nil {
+		meta.Locked = true
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("stat session lock: %w", err)
+	}
+
+	levelDat, err := nbt.ReadFromFile(filepath.Join(dir, "level.dat"))
+	if err != nil {
+		return nil, fmt.Errorf("read level.dat: %w", err)
+	}
+	for _, node := range levelDat.Query("Data.LastPlayed").Nodes() {
+		if lastPlayed, ok := nbt.Long(node); ok {
+			meta.LastPlayed = time.UnixMilli(lastPlayed)
+		}
+	}
+	return meta, nil
+}
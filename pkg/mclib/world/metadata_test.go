@@ -0,0 +1,60 @@
+package world
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sbreitf1/mctool/pkg/mclib/nbt"
+)
+
+func TestWorldMetadata(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "level.dat", `{Data:{LevelName:"meta",LastPlayed:1700000000123L}}`, nbt.CompressionGZip)
+	modTime := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(dir, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+
+	meta, err := WorldMetadata(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Locked {
+		t.Errorf("world without session.lock is reported as locked")
+	}
+	if !meta.ModTime.Equal(modTime) {
+		t.Errorf("ModTime = %v, want %v", meta.ModTime, modTime)
+	}
+	if want := time.UnixMilli(1700000000123); !meta.LastPlayed.Equal(want) {
+		t.Errorf("LastPlayed = %v, want %v", meta.LastPlayed, want)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "session.lock"), []byte("☃"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	meta, err = WorldMetadata(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !meta.Locked {
+		t.Errorf("world with session.lock is not reported as locked")
+	}
+}
+
+func TestWorldMetadataWithoutLastPlayed(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "level.dat", `{Data:{}}`, nbt.CompressionGZip)
+	meta, err := WorldMetadata(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !meta.LastPlayed.IsZero() {
+		t.Errorf("LastPlayed = %v, want zero", meta.LastPlayed)
+	}
+
+	if _, err := WorldMetadata(t.TempDir()); err == nil {
+		t.Errorf("expected error without level.dat")
+	}
+}
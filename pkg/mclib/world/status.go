@@ -0,0 +1,71 @@
+package world
+
+import (
+	"iter"
+	"strings"
+
+	"github.com/sbreitf1/mctool/pkg/mclib/nbt"
+	"github.com/sbreitf1/mctool/pkg/mclib/region"
+)
+
+var chunkStatusPaths = []string{
+	// since 1.18
+	"Status",
+	// before 1.18
+	"Level.Status",
+}
+
+// ChunkStatus returns the generation status of a chunk, e.g. "full", with
+// the "minecraft:" namespace removed.
+func ChunkStatus(chunk *nbt.File) (string, bool) {
+	for _, path := range chunkStatusPaths {
+		for _, node := range chunk.Query(path).Nodes() {
+			if status, ok := nbt.Str(node); ok {
+				return strings.TrimPrefix(status, "minecraft:"), true
+			}
+		}
+	}
+	return "", false
+}
+
+// ChunksWithStatus iterates all chunks whose status is one of statuses, or
+// all chunks if no status is given. Statuses may be given with or without
+// namespace. Chunks that cannot be read are skipped, as the iterator has no
+// way to report errors.
+func (w *World) ChunksWithStatus(statuses ...string) iter.Seq2[region.ChunkPos, *nbt.File] {
+	wanted := make(map[string]bool, len(statuses))
+	for _, status := range statuses {
+		wanted[strings.TrimPrefix(status, "minecraft:")] = true
+	}
+
+	return func(yield func(region.ChunkPos, *nbt.File) bool) {
+		files, _ := w.regionFiles("region")
+		for _, file := range files {
+			if !file.yieldChunksWithStatus(wanted, yield) {
+				return
+			}
+		}
+	}
+}
+
+func (f regionFile) yieldChunksWithStatus(wanted map[string]bool, yield func(region.ChunkPos, *nbt.File) bool) bool {
+	r, err := region.OpenRegion(f.Path)
+	if err != nil {
+		return true
+	}
+	defer r.Close()
+
+	for _, pos := range r.Chunks() {
+		chunk, err := r.ReadChunk(pos.X, pos.Z)
+		if err != nil {
+			continue
+		}
+		if status, _ := ChunkStatus(chunk); len(wanted) > 0 && !wanted[status] {
+			continue
+		}
+		if !yield(f.toWorldPos(pos), chunk) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,55 @@
+package world
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/sbreitf1/mctool/pkg/mclib/region"
+)
+
+func TestChunksWithStatus(t *testing.T) {
+	dir := t.TempDir()
+	writeTestRegion(t, dir, "region", 0, 0, map[region.ChunkPos]string{
+		{X: 0, Z: 0}: `{Status:"minecraft:full"}`,
+		{X: 1, Z: 0}: `{Status:"minecraft:noise"}`,
+		{X: 2, Z: 0}: `{Level:{Status:"full"}}`,
+		{X: 3, Z: 0}: `{Status:"minecraft:empty"}`,
+		{X: 4, Z: 0}: `{}`,
+	})
+	w, err := OpenWorld(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	collect := func(statuses ...string) []region.ChunkPos {
+		var positions []region.ChunkPos
+		for pos := range w.ChunksWithStatus(statuses...) {
+			positions = append(positions, pos)
+		}
+		return positions
+	}
+
+	full := []region.ChunkPos{{X: 0, Z: 0}, {X: 2, Z: 0}}
+	if positions := collect("full"); !slices.Equal(positions, full) {
+		t.Errorf("full chunks = %v, want %v", positions, full)
+	}
+	if positions := collect("minecraft:full"); !slices.Equal(positions, full) {
+		t.Errorf("namespaced full chunks = %v, want %v", positions, full)
+	}
+	if positions := collect("noise", "empty"); len(positions) != 2 {
+		t.Errorf("noise and empty chunks = %v", positions)
+	}
+	if positions := collect(); len(positions) != 5 {
+		t.Errorf("all chunks = %v", positions)
+	}
+
+	// stopping early must not yield further chunks
+	count := 0
+	for range w.ChunksWithStatus() {
+		count++
+		break
+	}
+	if count != 1 {
+		t.Errorf("iterated %d chunks after break", count)
+	}
+}
@@ -0,0 +1,68 @@
+package world
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/sbreitf1/mctool/pkg/mclib/nbt"
+	"github.com/sbreitf1/mctool/pkg/mclib/region"
+)
+
+type WalkOptions struct {
+	// SkipRegions ignores region files instead of passing their chunks.
+	SkipRegions bool
+}
+
+// WalkNBTFiles calls fn for every .dat and .nbt file below root and for every
+// chunk of the region files found there. Chunks are passed with their local
+// position appended to the region file path, e.g. "region/r.0.0.mca[3,4]".
+// Files that cannot be parsed are passed with nil and the error. Walking
+// stops once fn returns an error.
+func WalkNBTFiles(root string, fn func(path string, f *nbt.File, err error) error) error {
+	return WalkNBTFilesWithOptions(root, WalkOptions{}, fn)
+}
+
+func WalkNBTFilesWithOptions(root string, opts WalkOptions, fn func(path string, f *nbt.File, err error) error) error {
+	return filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return fn(path, nil, err)
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".dat", ".nbt":
+			f, err := nbt.ReadFromFile(path)
+			return fn(path, f, err)
+		case ".mca", ".mcr":
+			if opts.SkipRegions {
+				return nil
+			}
+			return walkRegionChunks(path, fn)
+		}
+		return nil
+	})
+}
+
+func walkRegionChunks(path string, fn func(path string, f *nbt.File, err error) error) error {
+	r, err := region.OpenRegion(path)
+	if err != nil {
+		return fn(path, nil, err)
+	}
+	defer r.Close()
+
+	for _, pos := range r.Chunks() {
+		chunk, err := r.ReadChunk(pos.X, pos.Z)
+		if errors.Is(err, region.ErrChunkNotPresent) {
+			continue
+		}
+		if err := fn(fmt.Sprintf("%s[%d,%d]", path, pos.X, pos.Z), chunk, err); err != nil {
+			return err
+		}
+	}
+	return nil
+}
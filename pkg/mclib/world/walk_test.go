@@ -0,0 +1,100 @@
+package world
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/sbreitf1/mctool/pkg/mclib/nbt"
+	"github.com/sbreitf1/mctool/pkg/mclib/region"
+)
+
+// writeTestFile writes SNBT as NBT file with the given compression below dir.
+func writeTestFile(t *testing.T, dir, name, snbt string, compression nbt.CompressionType) {
+	t.Helper()
+	data, err := nbt.Encode(snbt, compression)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWalkNBTFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "level.dat", `{Data:{LevelName:"walk"}}`, nbt.CompressionGZip)
+	writeTestFile(t, dir, "playerdata/uuid.dat", `{Health:20.0f}`, nbt.CompressionGZip)
+	writeTestFile(t, dir, "data/raids.dat", `{data:{}}`, nbt.CompressionNone)
+	writeTestFile(t, dir, "generated/minecraft/structures/house.NBT", `{size:[1,1,1]}`, nbt.CompressionZlib)
+	os.WriteFile(filepath.Join(dir, "broken.dat"), []byte("not nbt"), 0o644)
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0o644)
+	writeTestRegion(t, dir, "region", 0, 0, map[region.ChunkPos]string{
+		{X: 0, Z: 0}: `{xPos:0}`,
+		{X: 3, Z: 4}: `{xPos:3}`,
+	})
+	addEmptySlot(t, dir, "region", 0, 0, region.ChunkPos{X: 1, Z: 0})
+
+	walk := func(opts WalkOptions) ([]string, []string) {
+		var paths, failed []string
+		err := WalkNBTFilesWithOptions(dir, opts, func(path string, f *nbt.File, err error) error {
+			rel, _ := filepath.Rel(dir, path)
+			rel = filepath.ToSlash(rel)
+			if err != nil {
+				failed = append(failed, rel)
+			} else if f == nil {
+				t.Errorf("%s: passed without file and error", rel)
+			} else {
+				paths = append(paths, rel)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return paths, failed
+	}
+
+	paths, failed := walk(WalkOptions{})
+	want := []string{
+		"data/raids.dat",
+		"generated/minecraft/structures/house.NBT",
+		"level.dat",
+		"playerdata/uuid.dat",
+		"region/r.0.0.mca[0,0]",
+		"region/r.0.0.mca[3,4]",
+	}
+	if !slices.Equal(paths, want) {
+		t.Errorf("paths = %v, want %v", paths, want)
+	}
+	if !slices.Equal(failed, []string{"broken.dat"}) {
+		t.Errorf("failed = %v, want [broken.dat]", failed)
+	}
+
+	paths, _ = walk(WalkOptions{SkipRegions: true})
+	if !slices.Equal(paths, want[:4]) {
+		t.Errorf("paths without regions = %v, want %v", paths, want[:4])
+	}
+}
+
+func TestWalkNBTFilesStops(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "a.dat", `{}`, nbt.CompressionGZip)
+	writeTestFile(t, dir, "b.dat", `{}`, nbt.CompressionGZip)
+
+	errStop := errors.New("stop")
+	calls := 0
+	err := WalkNBTFiles(dir, func(string, *nbt.File, error) error {
+		calls++
+		return errStop
+	})
+	if !errors.Is(err, errStop) || calls != 1 {
+		t.Errorf("WalkNBTFiles() = %v after %d calls, want stop after 1 call", err, calls)
+	}
+}
@@ -0,0 +1,142 @@
+package world
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+
+	"github.com/sbreitf1/mctool/pkg/mclib/nbt"
+	"github.com/sbreitf1/mctool/pkg/mclib/region"
+)
+
+type World struct {
+	Dir string
+}
+
+func OpenWorld(dir string) (*World, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("open world: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("open world: %q is not a directory", dir)
+	}
+	return &World{Dir: dir}, nil
+}
+
+var regionFilePattern = regexp.MustCompile(`^r\.(-?\d+)\.(-?\d+)\.mca$`)
+
+type regionFile struct {
+	Path string
+	X, Z int
+}
+
+func (w *World) regionFiles(folder string) ([]regionFile, error) {
+	entries, err := os.ReadDir(filepath.Join(w.Dir, folder))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("list region files: %w", err)
+	}
+
+	files := make([]regionFile, 0, len(entries))
+	for _, entry := range entries {
+		match := regionFilePattern.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		x, _ := strconv.Atoi(match[1])
+		z, _ := strconv.Atoi(match[2])
+		files = append(files, regionFile{
+			Path: filepath.Join(w.Dir, folder, entry.Name()),
+			X:    x,
+			Z:    z,
+		})
+	}
+	return files, nil
+}
+
+func (w *World) openRegion(folder string, x, z int) (*region.Region, error) {
+	file := filepath.Join(w.Dir, folder, fmt.Sprintf("r.%d.%d.mca", x, z))
+	if _, err := os.Stat(file); os.IsNotExist(err) {
+		return nil, region.ErrChunkNotPresent
+	}
+	return region.OpenRegion(file)
+}
+
+// toWorldPos converts a region-local chunk position into absolute chunk coordinates.
+func (f regionFile) toWorldPos(pos region.ChunkPos) region.ChunkPos {
+	return region.ChunkPos{X: 32*f.X + pos.X, Z: 32*f.Z + pos.Z}
+}
+
+type ChunkStats struct {
+	Regions int
+	Chunks  int
+	// Min and Max span the bounding box of all generated chunks in absolute
+	// chunk coordinates. Both are zero if no chunk has been generated.
+	Min, Max region.ChunkPos
+}
+
+// ChunkStats only reads the location tables of all region files and
+// never decompresses chunk data.
+func (w *World) ChunkStats() (*ChunkStats, error) {
+	files, err := w.regionFiles("region")
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &ChunkStats{
+		Regions: len(files),
+	}
+	for _, file := range files {
+		r, err := region.OpenRegion(file.Path)
+		if err != nil {
+			return nil, fmt.Errorf("region %d,%d: %w", file.X, file.Z, err)
+		}
+		chunks := r.Chunks()
+		r.Close()
+
+		for _, localPos := range chunks {
+			pos := file.toWorldPos(localPos)
+			if stats.Chunks == 0 {
+				stats.Min, stats.Max = pos, pos
+			} else {
+				stats.Min.X, stats.Min.Z = min(stats.Min.X, pos.X), min(stats.Min.Z, pos.Z)
+				stats.Max.X, stats.Max.Z = max(stats.Max.X, pos.X), max(stats.Max.Z, pos.Z)
+			}
+			stats.Chunks++
+		}
+	}
+	return stats, nil
+}
+
+// forEachChunk parses every present chunk of the region files in folder one
+// after another. Positions passed to fn are absolute chunk coordinates.
+func (w *World) forEachChunk(folder string, fn func(pos region.ChunkPos, chunk *nbt.File) error) error {
+	files, err := w.regionFiles(folder)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if err := file.forEachChunk(fn); err != nil {
+			return fmt.Errorf("region %d,%d: %w", file.X, file.Z, err)
+		}
+	}
+	return nil
+}
+
+func (f regionFile) forEachChunk(fn func(pos region.ChunkPos, chunk *nbt.File) error) error {
+	r, err := region.OpenRegion(f.Path)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	return r.ForEachChunk(func(pos region.ChunkPos, chunk *nbt.File) error {
+		return fn(f.toWorldPos(pos), chunk)
+	})
+}
@@ -0,0 +1,126 @@
+package world
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sbreitf1/mctool/pkg/mclib/nbt"
+	"github.com/sbreitf1/mctool/pkg/mclib/region"
+)
+
+// writeTestRegion writes the region file r.<rx>.<rz>.mca to folder below dir.
+// chunks maps local chunk positions to the SNBT of their contents, which are
+// stored zlib compressed with one chunk per sector run.
+func writeTestRegion(t *testing.T, dir, folder string, rx, rz int, chunks map[region.ChunkPos]string) {
+	t.Helper()
+
+	header := make([]byte, 2*region.SectorSize)
+	var body bytes.Buffer
+	for pos, snbt := range chunks {
+		f, err := nbt.ParseSNBT(snbt)
+		if err != nil {
+			t.Fatalf("parse chunk %v: %v", pos, err)
+		}
+		var data bytes.Buffer
+		w := zlib.NewWriter(&data)
+		if err := nbt.WriteToStream(w, f); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		sector := 2 + body.Len()/region.SectorSize
+		sectors := (data.Len() + 5 + region.SectorSize - 1) / region.SectorSize
+		binary.BigEndian.PutUint32(header[4*(pos.X+32*pos.Z):], uint32(sector<<8|sectors))
+		binary.Write(&body, binary.BigEndian, uint32(data.Len()+1))
+		body.WriteByte(region.CompressionZlib)
+		body.Write(data.Bytes())
+		body.Write(make([]byte, sectors*region.SectorSize-data.Len()-5))
+	}
+
+	path := filepath.Join(dir, folder)
+	if err := os.MkdirAll(path, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(path, fmt.Sprintf("r.%d.%d.mca", rx, rz))
+	if err := os.WriteFile(file, append(header, body.Bytes()...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// addEmptySlot points the location entry of pos in the region file
+// r.<rx>.<rz>.mca below dir to a sector offset without any sectors, which
+// holds no chunk.
+func addEmptySlot(t *testing.T, dir, folder string, rx, rz int, pos region.ChunkPos) {
+	t.Helper()
+
+	file := filepath.Join(dir, folder, fmt.Sprintf("r.%d.%d.mca", rx, rz))
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	binary.BigEndian.PutUint32(data[4*(pos.X+32*pos.Z):], 2<<8)
+	if err := os.WriteFile(file, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestChunkStats(t *testing.T) {
+	dir := t.TempDir()
+	writeTestRegion(t, dir, "region", 0, 0, map[region.ChunkPos]string{
+		{X: 0, Z: 0}: `{}`,
+		{X: 5, Z: 3}: `{}`,
+	})
+	writeTestRegion(t, dir, "region", -1, 0, map[region.ChunkPos]string{
+		{X: 31, Z: 10}: `{}`,
+	})
+	writeTestRegion(t, dir, "region", 0, -1, nil)
+	// other files in the folder are ignored
+	os.WriteFile(filepath.Join(dir, "region", "notes.txt"), []byte("x"), 0o644)
+
+	w, err := OpenWorld(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats, err := w.ChunkStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ChunkStats{
+		Regions: 3,
+		Chunks:  3,
+		Min:     region.ChunkPos{X: -1, Z: 0},
+		Max:     region.ChunkPos{X: 5, Z: 10},
+	}
+	if *stats != want {
+		t.Errorf("ChunkStats() = %+v, want %+v", *stats, want)
+	}
+}
+
+func TestChunkStatsEmptyWorld(t *testing.T) {
+	w, err := OpenWorld(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats, err := w.ChunkStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *stats != (ChunkStats{}) {
+		t.Errorf("ChunkStats() = %+v, want zero stats", *stats)
+	}
+}
+
+func TestOpenWorldRejectsFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "level.dat")
+	os.WriteFile(file, nil, 0o644)
+	if _, err := OpenWorld(file); err == nil {
+		t.Errorf("expected error opening a file as world")
+	}
+}